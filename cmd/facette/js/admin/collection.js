@@ -14,8 +14,12 @@ function adminCollectionGetData() {
         data = {
             name: $pane.find('input[name=collection-name]').val(),
             description: $pane.find('textarea[name=collection-desc]').val(),
+            tags: splitTags($pane.find('input[name=collection-tags]').val()),
             parent: ($pane.find('input[name=collection-parent]').data('value') || {}).id,
-            entries: []
+            entries: [],
+            type: ($pane.data('dynamic') || {}).type || 0,
+            group: ($pane.data('dynamic') || {}).group || '',
+            templates: ($pane.data('dynamic') || {}).templates || null
         };
 
     listGetItems('step-1-graphs').each(function () {
@@ -30,8 +34,11 @@ function adminCollectionGetData() {
                 range: $range.val() || $range.attr('placeholder'),
                 sample: $item.find('input[name=graph-sample]').val(),
                 constants: $item.find('input[name=graph-constants]').val(),
-                percentiles: $item.find('input[name=graph-percentiles]').val()
-            }
+                percentiles: $item.find('input[name=graph-percentiles]').val(),
+                annotations: $item.find('input[name=graph-annotations]').val(),
+                annotation_tags: $item.find('input[name=graph-annotation-tags]').val()
+            },
+            attributes: ($item.data('value') || {}).attributes || null
         });
     });
 
@@ -247,12 +254,21 @@ function adminCollectionSetupTerminate() {
                 $item.find('input[name=graph-sample]').val(data.entries[i].options.sample || '');
                 $item.find('input[name=graph-constants]').val(data.entries[i].options.constants || '');
                 $item.find('input[name=graph-percentiles]').val(data.entries[i].options.percentiles || '');
+                $item.find('input[name=graph-annotations]').val(data.entries[i].options.annotations || '');
+                $item.find('input[name=graph-annotation-tags]').val(data.entries[i].options.annotation_tags || '');
             }
 
             $pane = paneMatch('collection-edit');
 
             $pane.find('input[name=collection-name]').val(data.name);
             $pane.find('textarea[name=collection-desc]').val(data.description);
+            $pane.find('input[name=collection-tags]').val((data.tags || []).join(', '));
+
+            $pane.data('dynamic', {
+                type: data.type,
+                group: data.group,
+                templates: data.templates
+            });
 
             if (data.parent) {
                 itemLoad(data.parent, 'collections').pipe(function (data) {
@@ -4,9 +4,12 @@ function adminGraphGetData() {
         data = {
             name: $pane.find('input[name=graph-name]').val(),
             description: $pane.find('textarea[name=graph-desc]').val(),
+            tags: splitTags($pane.find('input[name=graph-tags]').val()),
             type: parseInt($pane.find('select[name=graph-type]').val(), 10),
             stack_mode: parseInt($pane.find('select[name=stack-mode]').val(), 10),
-            stacks: adminGraphGetStacks()
+            stacks: adminGraphGetStacks(),
+            template: $pane.data('template') || false,
+            embeddable: $pane.find('select[name=graph-embeddable]').val() == '1'
         };
 
     return data;
@@ -150,6 +153,8 @@ function adminGraphCreateGroup(name, value) {
         type = 'avg';
     else if (value.type == OPER_GROUP_TYPE_SUM)
         type = 'sum';
+    else if (value.type == OPER_GROUP_TYPE_EXPR)
+        type = 'expr';
     else
         type = '';
 
@@ -237,6 +242,8 @@ function adminGraphCreateProxy(type, item, list) {
             value.type = 'avg';
         else if (value.type == OPER_GROUP_TYPE_SUM)
             value.type = 'sum';
+        else if (value.type == OPER_GROUP_TYPE_EXPR)
+            value.type = 'expr';
         else
             delete value.type;
     }
@@ -1232,13 +1239,32 @@ function adminGraphSetupTerminate() {
                     $itemOper = data.stacks[i].groups[j].type !== OPER_GROUP_TYPE_NONE ? adminGraphCreateGroup(null, {
                         name: data.stacks[i].groups[j].name,
                         type: data.stacks[i].groups[j].type,
+                        expression: data.stacks[i].groups[j].expression,
+                        fill: data.stacks[i].groups[j].fill,
+                        transforms: data.stacks[i].groups[j].transforms,
+                        forecast: data.stacks[i].groups[j].forecast,
+                        anomaly: data.stacks[i].groups[j].anomaly,
                         options: data.stacks[i].groups[j].options
                     }) : null;
 
                     for (k in data.stacks[i].groups[j].series) {
+                        // Merge single serie group processing into the serie one
                         $itemSerie = adminGraphCreateSerie(null, $.extend(data.stacks[i].groups[j].series[k], {
                             options: data.stacks[i].groups[j].type === OPER_GROUP_TYPE_NONE ?
-                                data.stacks[i].groups[j].options : null
+                                data.stacks[i].groups[j].options : null,
+                            fill: data.stacks[i].groups[j].type === OPER_GROUP_TYPE_NONE ?
+                                data.stacks[i].groups[j].series[k].fill || data.stacks[i].groups[j].fill :
+                                data.stacks[i].groups[j].series[k].fill,
+                            transforms: data.stacks[i].groups[j].type === OPER_GROUP_TYPE_NONE ?
+                                (data.stacks[i].groups[j].series[k].transforms || [])
+                                    .concat(data.stacks[i].groups[j].transforms || []) :
+                                data.stacks[i].groups[j].series[k].transforms,
+                            forecast: data.stacks[i].groups[j].type === OPER_GROUP_TYPE_NONE ?
+                                data.stacks[i].groups[j].series[k].forecast || data.stacks[i].groups[j].forecast :
+                                data.stacks[i].groups[j].series[k].forecast,
+                            anomaly: data.stacks[i].groups[j].type === OPER_GROUP_TYPE_NONE ?
+                                data.stacks[i].groups[j].series[k].anomaly || data.stacks[i].groups[j].anomaly :
+                                data.stacks[i].groups[j].series[k].anomaly
                         }));
 
                         if ($itemOper)
@@ -1256,6 +1282,9 @@ function adminGraphSetupTerminate() {
 
             $pane.find('input[name=graph-name]').val(data.name);
             $pane.find('textarea[name=graph-desc]').val(data.description);
+            $pane.find('input[name=graph-tags]').val((data.tags || []).join(', '));
+
+            $pane.data('template', data.template);
 
             $pane.find('select[name=graph-type]').val(data.type).trigger({
                 type: 'change',
@@ -1267,6 +1296,11 @@ function adminGraphSetupTerminate() {
                 _init: true
             });
 
+            $pane.find('select[name=graph-embeddable]').val(data.embeddable ? '1' : '0').trigger({
+                type: 'change',
+                _init: true
+            });
+
             if ($listMetrics.data('counter') === 0)
                 listSay($listMetrics, $.t('metric.mesg_none'));
 
@@ -14,6 +14,7 @@ function adminGroupGetData() {
         data = {
             name: $pane.find('input[name=group-name]').val(),
             description: $pane.find('textarea[name=group-desc]').val(),
+            tags: splitTags($pane.find('input[name=group-tags]').val()),
             entries: []
         };
 
@@ -309,6 +310,7 @@ function adminGroupSetupTerminate() {
 
             $pane.find('input[name=group-name]').val(data.name);
             $pane.find('textarea[name=group-desc]').val(data.description);
+            $pane.find('input[name=group-tags]').val((data.tags || []).join(', '));
 
             if ($listItems.data('counter') === 0)
                 listSay($listItems, $.t('item.mesg_none'));
@@ -0,0 +1,80 @@
+
+var KIOSK_TIMEOUTS = {};
+
+function browseKioskSetupTerminate() {
+    var $kiosk = $('[data-kiosk]'),
+        kioskOpts;
+
+    if ($kiosk.length === 0)
+        return;
+
+    kioskOpts = $kiosk.opts('kiosk');
+
+    $kiosk.data({
+        options: {
+            interval: parseInt(kioskOpts.interval, 10) || 0,
+            refresh: parseInt(kioskOpts.refresh, 10) || 0
+        },
+        page: 0
+    });
+
+    browseKioskSchedule($kiosk);
+}
+
+function browseKioskGoto(index) {
+    var $kiosk = $('[data-kiosk]'),
+        $pages = $kiosk.find('[data-kioskpage]'),
+        $page;
+
+    if ($pages.length === 0)
+        return;
+
+    index = index % $pages.length;
+    $page = $pages.eq(index);
+
+    $pages.not($page).hide();
+    $page.show();
+
+    $kiosk.data('page', index);
+
+    // Draw graphs once visible for them to fit their container
+    browseKioskDraw($page);
+    browseKioskSchedule($kiosk);
+}
+
+function browseKioskDraw(page) {
+    page.find('[data-graph]').each(function () {
+        graphDraw($(this));
+    });
+}
+
+function browseKioskRefresh(kiosk) {
+    var kioskOpts = kiosk.data('options');
+
+    if (KIOSK_TIMEOUTS.refresh)
+        clearTimeout(KIOSK_TIMEOUTS.refresh);
+
+    if (kioskOpts.refresh <= 0)
+        return;
+
+    KIOSK_TIMEOUTS.refresh = setTimeout(function () {
+        browseKioskDraw(kiosk.find('[data-kioskpage]').eq(kiosk.data('page')));
+        browseKioskRefresh(kiosk);
+    }, kioskOpts.refresh * 1000);
+}
+
+function browseKioskSchedule(kiosk) {
+    var kioskOpts = kiosk.data('options');
+
+    if (KIOSK_TIMEOUTS.page)
+        clearTimeout(KIOSK_TIMEOUTS.page);
+
+    // Cycle through pages, the current page being refreshed meanwhile
+    if (kioskOpts.interval > 0 && kiosk.find('[data-kioskpage]').length > 1) {
+        KIOSK_TIMEOUTS.page = setTimeout(function () {
+            browseKioskGoto(kiosk.data('page') + 1);
+        }, kioskOpts.interval * 1000);
+    }
+
+    browseKioskRefresh(kiosk);
+}
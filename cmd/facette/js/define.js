@@ -13,6 +13,7 @@ var EVENT_KEY_TAB    = 9,
     OPER_GROUP_TYPE_NONE = 0,
     OPER_GROUP_TYPE_AVG  = 1,
     OPER_GROUP_TYPE_SUM  = 2,
+    OPER_GROUP_TYPE_EXPR = 3,
 
     GRAPH_TYPE_AREA   = 0,
     GRAPH_TYPE_LINE   = 1,
@@ -32,6 +33,39 @@ var EVENT_KEY_TAB    = 9,
     GRAPH_DRAW_DELAY        = 250,
     GRAPH_LEGEND_ROW_HEIGHT = 24,
 
+    GRAPH_THEME_DARK = {
+        chart: {
+            backgroundColor: '#222'
+        },
+        subtitle: {
+            style: {
+                color: '#999'
+            }
+        },
+        title: {
+            style: {
+                color: '#ccc'
+            }
+        },
+        xAxis: {
+            labels: {
+                style: {
+                    color: '#ccc'
+                }
+            },
+            lineColor: '#555',
+            tickColor: '#555'
+        },
+        yAxis: {
+            gridLineColor: '#444',
+            labels: {
+                style: {
+                    color: '#ccc'
+                }
+            }
+        }
+    },
+
     SAVE_MODE_CLONE    = 0,
     SAVE_MODE_VOLATILE = 1,
 
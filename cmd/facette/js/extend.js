@@ -31,6 +31,10 @@ $.fn.extend({
             elementTop = $element.offset().top,
             elementBottom = elementTop + $element.height();
 
+        // Hidden elements are never considered as visible
+        if (!$element.is(':visible'))
+            return false;
+
         return elementTop <= viewBottom && elementBottom >= viewTop;
     },
 
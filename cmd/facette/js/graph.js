@@ -40,6 +40,10 @@ function graphDraw(graph, postpone, delay) {
 
             graph.find('.graphctrl .ranges').hide();
 
+            // Remove controls from frozen snapshot graphs or when explicitly disabled
+            if (graph.data('options').snapshot || graph.data('options').controls == 'false')
+                graph.find('.graphctrl').remove();
+
             graph.find('.placeholder').text(graph.data('options').title || 'N/A');
         }
     }
@@ -64,9 +68,21 @@ function graphDraw(graph, postpone, delay) {
                 graphOpts.preview = graphOpts.preview &&
                     graphOpts.preview.trim().toLowerCase() == 'true' ? true : false;
 
+            if (typeof graphOpts.annotations != 'boolean')
+                graphOpts.annotations = graphOpts.annotations &&
+                    graphOpts.annotations.trim().toLowerCase() == 'true' ? true : false;
+
             if (typeof graphOpts.zoom != 'boolean')
                 graphOpts.zoom = graphOpts.zoom && graphOpts.zoom.trim().toLowerCase() == 'false' ? false : true;
 
+            if (typeof graphOpts.legend != 'boolean')
+                graphOpts.legend = graphOpts.legend && graphOpts.legend.trim().toLowerCase() == 'false' ? false : true;
+
+            if (graphOpts.refresh)
+                graphOpts.refresh = parseInt(graphOpts.refresh, 10);
+            else
+                delete graphOpts.refresh;
+
             if (graphOpts.sample)
                 graphOpts.sample = parseInt(graphOpts.sample, 10);
             else
@@ -82,9 +98,20 @@ function graphDraw(graph, postpone, delay) {
             query = {
                 time: graphOpts.time,
                 range: graphOpts.range,
+                start: graphOpts.start,
+                end: graphOpts.end,
+                timezone: graphOpts.timezone,
                 sample: graphOpts.sample,
+                downsample: graphOpts.downsample,
                 percentiles: graphOpts.percentiles ? $.map(graphOpts.percentiles.split(','), function (x) {
                     return parseFloat(x.trim());
+                }) : undefined,
+                annotations: graphOpts.annotations || undefined,
+                annotation_tags: graphOpts.annotations && graphOpts.annotation_tags ? $.map(graphOpts.annotation_tags.split(','), function (x) {
+                    return x.trim();
+                }) : undefined,
+                shifts: graphOpts.shifts ? $.map(graphOpts.shifts.split(','), function (x) {
+                    return x.trim();
                 }) : undefined
             };
 
@@ -99,10 +126,19 @@ function graphDraw(graph, postpone, delay) {
                     query.metric = graphOpts.metric;
             } else {
                 query.graph = graph.attr('data-graph');
+
+                if (graph.attr('data-attrsopts'))
+                    query.attributes = graph.opts('attrs');
             }
 
-            return $.ajax({
-                url: urlPrefix + '/library/graphs/plots',
+            return $.ajax(graphOpts.snapshot ? {
+                url: urlPrefix + '/snapshots/' + graphOpts.snapshot + '/plots/' + graph.attr('data-graph'),
+                type: 'GET',
+                dataType: 'json'
+            } : {
+                // Embedded graphs plots are only served by the embedding endpoint, checking access token
+                url: urlPrefix + (graphOpts.embed ? '/embed/plots' + (graphOpts.token ?
+                    '?token=' + encodeURIComponent(graphOpts.token) : '') : '/library/graphs/plots'),
                 type: 'POST',
                 contentType: 'application/json',
                 data: JSON.stringify(query),
@@ -124,6 +160,8 @@ function graphDraw(graph, postpone, delay) {
 
                     graph.find('.placeholder').text(data.message);
 
+                    graphScheduleRefresh(graph);
+
                     return;
                 } else {
                     graph.children('.graphctrl')
@@ -144,7 +182,7 @@ function graphDraw(graph, postpone, delay) {
                         borderRadius: 0,
                         events: {
                             load: function () {
-                                if (!graphOpts.preview)
+                                if (!graphOpts.preview && graphOpts.legend)
                                     Highcharts.drawTable.apply(this, [info]);
                             },
 
@@ -215,6 +253,8 @@ function graphDraw(graph, postpone, delay) {
                     xAxis: {
                         max: endTime.valueOf(),
                         min: startTime.valueOf(),
+                        plotBands: [],
+                        plotLines: [],
                         type: 'datetime'
                     },
                     yAxis: {
@@ -304,30 +344,110 @@ function graphDraw(graph, postpone, delay) {
 
                 for (i in data.stacks) {
                     for (j in data.stacks[i].series) {
-                        highchartOpts.series.push({
-                            id: data.stacks[i].series[j].name,
-                            name: data.stacks[i].series[j].name,
-                            stack: data.stacks[i].name,
-                            data: data.stacks[i].series[j].plots,
-                            color: data.stacks[i].series[j].options ? data.stacks[i].series[j].options.color : null
-                        });
+                        // Draw comparison series apart from their reference stacks
+                        if (data.stacks[i].series[j].comparison) {
+                            highchartOpts.series.push({
+                                id: data.stacks[i].series[j].name,
+                                name: data.stacks[i].series[j].name,
+                                stack: data.stacks[i].name + ' (' + data.stacks[i].series[j].shift + ')',
+                                data: data.stacks[i].series[j].plots,
+                                dashStyle: 'ShortDash',
+                                connectNulls: data.stacks[i].series[j].fill == 'drop'
+                            });
+                        } else if (data.stacks[i].series[j].forecast) {
+                            // Draw forecast series and their confidence bands as lines apart from reference stacks
+                            highchartOpts.series.push({
+                                id: data.stacks[i].series[j].name,
+                                name: data.stacks[i].series[j].name,
+                                type: 'line',
+                                stack: data.stacks[i].name + ' (forecast)',
+                                data: data.stacks[i].series[j].plots,
+                                color: data.stacks[i].series[j].options ? data.stacks[i].series[j].options.color :
+                                    null,
+                                dashStyle: data.stacks[i].series[j].band ? 'Dot' : 'Dash'
+                            });
+                        } else if (data.stacks[i].series[j].anomaly) {
+                            // Draw anomaly bands as lines apart from reference stacks
+                            highchartOpts.series.push({
+                                id: data.stacks[i].series[j].name,
+                                name: data.stacks[i].series[j].name,
+                                type: 'line',
+                                stack: data.stacks[i].name + ' (anomaly)',
+                                data: data.stacks[i].series[j].plots,
+                                color: data.stacks[i].series[j].options ? data.stacks[i].series[j].options.color :
+                                    null,
+                                dashStyle: 'Dot'
+                            });
+                        } else {
+                            highchartOpts.series.push({
+                                id: data.stacks[i].series[j].name,
+                                name: data.stacks[i].series[j].name,
+                                stack: data.stacks[i].name,
+                                data: data.stacks[i].series[j].plots,
+                                color: data.stacks[i].series[j].options ? data.stacks[i].series[j].options.color :
+                                    null,
+                                connectNulls: data.stacks[i].series[j].fill == 'drop'
+                            });
+                        }
 
                         info[data.stacks[i].series[j].name] = data.stacks[i].series[j].info;
+
+                        // Shade anomalous intervals
+                        $.each(data.stacks[i].series[j].anomalies || [], function (k, item) { /*jshint unused: true */
+                            highchartOpts.xAxis.plotBands.push({
+                                color: 'rgba(238, 51, 0, 0.1)',
+                                from: moment(item.start).valueOf(),
+                                to: moment(item.end).valueOf()
+                            });
+                        });
                     }
                 }
 
+                // Mark annotations as lines for single time events and bands for time ranges
+                $.each(data.annotations || [], function (i, item) { /*jshint unused: true */
+                    var marker = {
+                        color: item.end ? 'rgba(0, 136, 204, 0.1)' : '#08c',
+                        label: {
+                            text: !graphOpts.preview ? item.title : null,
+                            style: {
+                                color: '#08c'
+                            }
+                        },
+                        zIndex: 5
+                    };
+
+                    if (item.end) {
+                        highchartOpts.xAxis.plotBands.push($.extend(marker, {
+                            from: moment(item.start).valueOf(),
+                            to: moment(item.end).valueOf()
+                        }));
+                    } else {
+                        highchartOpts.xAxis.plotLines.push($.extend(marker, {
+                            value: moment(item.start).valueOf(),
+                            width: 1,
+                            dashStyle: 'ShortDash'
+                        }));
+                    }
+                });
+
+                // Apply graph theme
+                if (graphOpts.theme == 'dark')
+                    $.extend(true, highchartOpts, GRAPH_THEME_DARK);
+
                 // Prepare legend spacing
-                if (!graphOpts.preview)
+                if (!graphOpts.preview && graphOpts.legend)
                     highchartOpts.chart.spacingBottom = highchartOpts.series.length * GRAPH_LEGEND_ROW_HEIGHT +
                         highchartOpts.chart.spacingBottom * 2;
 
                 $container = graph.children('.graphcntr');
 
-                if (!graphOpts.preview && !$container.highcharts())
+                if (!graphOpts.preview && graphOpts.legend && !$container.highcharts())
                     $container.height($container.height() + highchartOpts.chart.spacingBottom);
 
                 $container.highcharts(highchartOpts);
                 $deferred.resolve();
+
+                graphScheduleRefresh(graph);
             }).fail(function () {
                 graph.children('.graphctrl')
                     .attr('disabled', 'disabled')
@@ -339,6 +459,8 @@ function graphDraw(graph, postpone, delay) {
                     .text($.t('graph.mesg_load_failed'));
 
                 $deferred.resolve();
+
+                graphScheduleRefresh(graph);
             });
         }, delay);
     }).promise();
@@ -606,6 +728,19 @@ function graphHandleQueue(force) {
     }).promise();
 }
 
+function graphScheduleRefresh(graph) {
+    var graphOpts = graph.data('options');
+
+    if (!graphOpts || !graphOpts.refresh)
+        return;
+
+    clearTimeout(graph.data('refreshTimeout'));
+
+    graph.data('refreshTimeout', setTimeout(function () {
+        graphDraw(graph);
+    }, graphOpts.refresh * 1000));
+}
+
 function graphSetupTerminate() {
     var $graphs = $('[data-graph]');
 
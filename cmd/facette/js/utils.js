@@ -54,6 +54,12 @@ function splitAttributeValue(attrValue) {
     return result;
 }
 
+function splitTags(value) {
+    return $.grep($.map(value.split(','), $.trim), function (tag) {
+        return tag !== '';
+    });
+}
+
 function timeToRange(duration) {
     var ranges = {
             d: 86400000,
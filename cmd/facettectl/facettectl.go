@@ -11,6 +11,8 @@ import (
 
 const (
 	cmdUsage = `Usage: %s [OPTIONS] reload
+       %[1]s [OPTIONS] export COLLECTION FILE
+       %[1]s [OPTIONS] import FILE
        %[1]s [OPTIONS] useradd NAME
        %[1]s [OPTIONS] userdel NAME
        %[1]s [OPTIONS] userlist
@@ -18,6 +20,8 @@ const (
 
 Commands:
    reload    send reload signal to server
+   export    export a collection into a bundle file
+   import    import items from a bundle file
    useradd   add a new user
    userdel   remove an existing user
    userlist  list existing users
@@ -25,15 +29,19 @@ Commands:
 )
 
 var (
-	flagConfig string
-	flagDebug  int
-	flagHelp   bool
+	flagConfig   string
+	flagDebug    int
+	flagHelp     bool
+	flagPreserve bool
+	flagStrategy string
 )
 
 func init() {
 	flag.StringVar(&flagConfig, "c", config.DefaultConfigFile, "configuration file path")
 	flag.IntVar(&flagDebug, "d", 0, "debugging level")
 	flag.BoolVar(&flagHelp, "h", false, "display this help and exit")
+	flag.BoolVar(&flagPreserve, "p", false, "preserve items identifiers on import")
+	flag.StringVar(&flagStrategy, "s", "", "import conflict strategy (skip, overwrite or rename)")
 	flag.Usage = func() { utils.PrintUsage(os.Stderr, cmdUsage) }
 	flag.Parse()
 
@@ -64,6 +72,8 @@ func main() {
 		handler = handleUser
 	case "reload":
 		handler = handleServer
+	case "export", "import":
+		handler = handleLibrary
 	default:
 		utils.PrintUsage(os.Stderr, cmdUsage)
 		os.Exit(1)
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/facette/facette/pkg/config"
+	"github.com/facette/facette/pkg/library"
+)
+
+func handleLibrary(config *config.Config, args []string) error {
+	if flagDebug == 0 {
+		log.SetOutput(ioutil.Discard)
+	}
+
+	cmd := &cmdLibrary{library: library.NewLibrary(config, nil, flagDebug)}
+
+	if err := cmd.library.Refresh(); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "export":
+		return cmd.export(args[1:])
+	case "import":
+		return cmd.load(args[1:])
+	}
+
+	return nil
+}
+
+type cmdLibrary struct {
+	library *library.Library
+}
+
+func (cmd *cmdLibrary) export(args []string) error {
+	if len(args) != 2 {
+		return os.ErrInvalid
+	}
+
+	bundle, err := cmd.library.ExportCollection(args[0])
+	if os.IsNotExist(err) {
+		return fmt.Errorf("collection `%s' not found", args[0])
+	} else if err != nil {
+		return err
+	}
+
+	fd, err := os.OpenFile(args[1], os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	defer fd.Close()
+
+	if strings.HasSuffix(args[1], ".tar") {
+		return bundle.WriteTar(fd)
+	}
+
+	output, err := json.MarshalIndent(bundle, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fd.Write(append(output, '\n'))
+
+	return err
+}
+
+func (cmd *cmdLibrary) load(args []string) error {
+	var bundle *library.Bundle
+
+	if len(args) != 1 {
+		return os.ErrInvalid
+	}
+
+	strategy, err := library.ParseImportStrategy(flagStrategy)
+	if err != nil {
+		return err
+	}
+
+	fd, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+
+	defer fd.Close()
+
+	if strings.HasSuffix(args[0], ".tar") {
+		if bundle, err = library.ReadBundleTar(fd); err != nil {
+			return err
+		}
+	} else {
+		data, err := ioutil.ReadAll(fd)
+		if err != nil {
+			return err
+		}
+
+		bundle = &library.Bundle{}
+		if err = json.Unmarshal(data, bundle); err != nil {
+			return err
+		}
+	}
+
+	report, err := cmd.library.ImportBundle(bundle, strategy, flagPreserve)
+	for _, entry := range report {
+		fmt.Printf("%s: %s `%s' (%s)\n", entry.Action, strings.TrimSuffix(entry.Type, "s"), entry.Name, entry.ID)
+	}
+
+	if os.IsExist(err) {
+		return fmt.Errorf("conflicting items found, please specify an import strategy")
+	}
+
+	return err
+}
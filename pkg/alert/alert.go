@@ -0,0 +1,377 @@
+// Package alert implements the periodic evaluation of the library alert rules.
+package alert
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/types"
+)
+
+const (
+	// StateOK represents the state of an alert rule whose conditions are not met.
+	StateOK = "ok"
+	// StateWarning represents the state of an alert rule whose warning condition is met.
+	StateWarning = "warning"
+	// StateCritical represents the state of an alert rule whose critical condition is met.
+	StateCritical = "critical"
+	// StateUnknown represents the state of an alert rule that could not be evaluated.
+	StateUnknown = "unknown"
+)
+
+// FetchFunc represents a function returning the information statistics of the series an alert rule references,
+// computed over a given time range and indexed by serie name.
+type FetchFunc func(rule *library.Alert, startTime, endTime time.Time) (map[string]map[string]types.PlotValue, error)
+
+// State represents the evaluation state of an alert rule.
+type State struct {
+	ID      string
+	Name    string
+	State   string
+	Serie   string
+	Value   types.PlotValue
+	Message string
+	Since   time.Time
+	Checked time.Time
+
+	pending      string
+	pendingSince time.Time
+	next         time.Time
+	running      bool
+}
+
+// SkipFunc represents a function returning whether alert rules evaluation must be skipped (e.g. while the service is
+// loading).
+type SkipFunc func() bool
+
+// HandlerFunc represents a function called after each evaluation of an alert rule with its resulting state and the
+// state it had before.
+type HandlerFunc func(rule *library.Alert, state *State, previous string)
+
+// Scheduler represents the main structure of an alert rules scheduler instance.
+type Scheduler struct {
+	Library    *library.Library
+	fetch      FetchFunc
+	handlers   []HandlerFunc
+	skip       SkipFunc
+	states     map[string]*State
+	stopChan   chan bool
+	mutex      sync.Mutex
+	debugLevel int
+}
+
+// AddHandler registers a function to be called after each alert rule evaluation.
+func (scheduler *Scheduler) AddHandler(handler HandlerFunc) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	scheduler.handlers = append(scheduler.handlers, handler)
+}
+
+// SetSkip registers a function called on each tick, evaluations being skipped as long as it returns true.
+func (scheduler *Scheduler) SetSkip(skip SkipFunc) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	scheduler.skip = skip
+}
+
+// Run evaluates the library alert rules on their interval until the scheduler is stopped.
+func (scheduler *Scheduler) Run() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	log.Println("INFO: alert scheduler started")
+
+	for {
+		select {
+		case <-scheduler.stopChan:
+			log.Println("INFO: alert scheduler stopped")
+			return
+
+		case now := <-ticker.C:
+			scheduler.mutex.Lock()
+			skip := scheduler.skip
+			scheduler.mutex.Unlock()
+
+			if skip != nil && skip() {
+				continue
+			}
+
+			go scheduler.Check(now)
+		}
+	}
+}
+
+// Stop stops the scheduler.
+func (scheduler *Scheduler) Stop() {
+	close(scheduler.stopChan)
+}
+
+// Check evaluates the alert rules due at a given time, returning once their evaluation is completed.
+func (scheduler *Scheduler) Check(now time.Time) {
+	wait := &sync.WaitGroup{}
+
+	scheduler.mutex.Lock()
+
+	for id, rule := range scheduler.Library.GetAlerts() {
+		if rule.Disabled {
+			continue
+		}
+
+		state, ok := scheduler.states[id]
+		if !ok {
+			state = &State{ID: id, State: StateUnknown, Value: types.PlotValue(math.NaN()), Since: now}
+			scheduler.states[id] = state
+		}
+
+		// Skip rules not yet due or still being evaluated
+		if state.running || now.Before(state.next) {
+			continue
+		}
+
+		state.running = true
+
+		wait.Add(1)
+
+		go func(rule *library.Alert, state *State) {
+			defer wait.Done()
+
+			// Prevent a failing rule evaluation from crashing the whole process
+			defer func() {
+				if data := recover(); data != nil {
+					scheduler.abort(rule, state, now, fmt.Sprintf("evaluation failed: %v", data))
+				}
+			}()
+
+			scheduler.evaluate(rule, state, now)
+		}(rule, state)
+	}
+
+	scheduler.mutex.Unlock()
+
+	wait.Wait()
+}
+
+// States returns a copy of the evaluation states of the enabled library alert rules.
+func (scheduler *Scheduler) States() []*State {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	result := make([]*State, 0)
+
+	for id, rule := range scheduler.Library.GetAlerts() {
+		if state, ok := scheduler.states[id]; ok && !rule.Disabled {
+			result = append(result, scheduler.copyState(rule, state))
+		}
+	}
+
+	return result
+}
+
+// State returns a copy of the evaluation state of an alert rule, or nil if not yet evaluated or disabled.
+func (scheduler *Scheduler) State(id string) *State {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	rule, ok := scheduler.Library.GetAlerts()[id]
+	if !ok || rule.Disabled {
+		return nil
+	}
+
+	state, ok := scheduler.states[id]
+	if !ok {
+		return nil
+	}
+
+	return scheduler.copyState(rule, state)
+}
+
+func (scheduler *Scheduler) copyState(rule *library.Alert, state *State) *State {
+	result := *state
+	result.Name = rule.Name
+
+	return &result
+}
+
+func (scheduler *Scheduler) evaluate(rule *library.Alert, state *State, now time.Time) {
+	var (
+		infos    map[string]map[string]types.PlotValue
+		status   = StateUnknown
+		serie    string
+		value    = types.PlotValue(math.NaN())
+		message  string
+		duration time.Duration
+		interval = time.Minute
+	)
+
+	window, ruleDuration, ruleInterval, err := rule.GetDurations()
+	if err == nil {
+		duration, interval = ruleDuration, ruleInterval
+
+		infos, err = scheduler.fetch(rule, now.Add(-window), now)
+	}
+
+	if err != nil {
+		message = err.Error()
+	} else if status, serie, value = Evaluate(rule, infos); status == StateUnknown {
+		message = "no data available"
+	}
+
+	if scheduler.debugLevel > 1 {
+		log.Printf("DEBUG: alert `%s' evaluated to %s (serie: `%s', value: %g)", rule.Name, status, serie, value)
+	}
+
+	scheduler.mutex.Lock()
+
+	previous := state.State
+
+	state.running = false
+	state.next = now.Add(interval)
+
+	state.Serie = serie
+	state.Value = value
+	state.Message = message
+
+	// Apply state change immediately on first evaluation, or once the new state has lasted for the rule duration
+	if status == state.State {
+		state.pending = ""
+	} else {
+		if status != state.pending {
+			state.pending, state.pendingSince = status, now
+		}
+
+		if state.Checked.IsZero() || now.Sub(state.pendingSince) >= duration {
+			log.Printf("INFO: alert `%s' changed from %s to %s", rule.Name, state.State, status)
+
+			state.State = status
+			state.Since = now
+			state.pending = ""
+		}
+	}
+
+	state.Checked = now
+
+	result := scheduler.copyState(rule, state)
+	handlers := scheduler.handlers
+
+	scheduler.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler(rule, result, previous)
+	}
+}
+
+func (scheduler *Scheduler) abort(rule *library.Alert, state *State, now time.Time, message string) {
+	log.Printf("ERROR: alert `%s' %s", rule.Name, message)
+
+	interval := time.Minute
+	if _, _, ruleInterval, err := rule.GetDurations(); err == nil {
+		interval = ruleInterval
+	}
+
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	state.running = false
+	state.next = now.Add(interval)
+
+	if state.State != StateUnknown {
+		state.State = StateUnknown
+		state.Since = now
+	}
+
+	state.Serie = ""
+	state.Value = types.PlotValue(math.NaN())
+	state.Message = message
+	state.pending = ""
+	state.Checked = now
+}
+
+// Evaluate returns the state of an alert rule given the information statistics of its series, along with the name
+// and the statistic value of the serie responsible for it. The most severe state among the series is returned.
+func Evaluate(rule *library.Alert, infos map[string]map[string]types.PlotValue) (string, string, types.PlotValue) {
+	var (
+		status = StateUnknown
+		serie  string
+		value  = types.PlotValue(math.NaN())
+	)
+
+	names := make([]string, 0)
+	for name := range infos {
+		if rule.Serie == "" || name == rule.Serie {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		current, ok := infos[name][rule.GetStatistic()]
+		if !ok || math.IsNaN(float64(current)) {
+			continue
+		}
+
+		result := StateOK
+
+		if rule.Critical != nil && compare(float64(current), rule.Operator, *rule.Critical) {
+			result = StateCritical
+		} else if rule.Warning != nil && compare(float64(current), rule.Operator, *rule.Warning) {
+			result = StateWarning
+		}
+
+		if severity(result) > severity(status) {
+			status, serie, value = result, name, current
+		}
+	}
+
+	return status, serie, value
+}
+
+func compare(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	}
+
+	return false
+}
+
+func severity(state string) int {
+	switch state {
+	case StateOK:
+		return 1
+	case StateWarning:
+		return 2
+	case StateCritical:
+		return 3
+	}
+
+	return 0
+}
+
+// NewScheduler creates a new instance of alert rules scheduler.
+func NewScheduler(library *library.Library, fetch FetchFunc, debugLevel int) *Scheduler {
+	return &Scheduler{
+		Library:    library,
+		fetch:      fetch,
+		states:     make(map[string]*State),
+		stopChan:   make(chan bool),
+		debugLevel: debugLevel,
+	}
+}
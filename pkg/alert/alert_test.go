@@ -0,0 +1,157 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/types"
+)
+
+func Test_Evaluate(test *testing.T) {
+	warning, critical := 80.0, 90.0
+
+	rule := &library.Alert{Operator: ">", Warning: &warning, Critical: &critical}
+
+	infos := map[string]map[string]types.PlotValue{
+		"host1": {"avg": 50, "max": 95},
+		"host2": {"avg": 85, "max": 88},
+		"host3": {"avg": types.PlotValue(math.NaN()), "max": 99},
+	}
+
+	for _, entry := range []struct {
+		statistic string
+		serie     string
+		expected  [2]string
+	}{
+		{"", "", [2]string{StateWarning, "host2"}},
+		{"max", "", [2]string{StateCritical, "host1"}},
+		{"", "host1", [2]string{StateOK, "host1"}},
+		{"", "host3", [2]string{StateUnknown, ""}},
+		{"min", "", [2]string{StateUnknown, ""}},
+	} {
+		rule.Statistic, rule.Serie = entry.statistic, entry.serie
+
+		if status, serie, _ := Evaluate(rule, infos); status != entry.expected[0] || serie != entry.expected[1] {
+			test.Logf("\nExpected %s (%s)\nbut got  %s (%s)", entry.expected[0], entry.expected[1], status, serie)
+			test.Fail()
+		}
+	}
+}
+
+func Test_SchedulerCheck(test *testing.T) {
+	critical := 10.0
+
+	rule := &library.Alert{Operator: ">=", Critical: &critical, Duration: "2m", Interval: "1m"}
+	rule.ID, rule.Name = "rule1", "rule1"
+
+	value := types.PlotValue(5)
+	fail := false
+
+	fetch := func(rule *library.Alert, startTime, endTime time.Time) (map[string]map[string]types.PlotValue, error) {
+		if fail {
+			return nil, fmt.Errorf("fetch failed")
+		}
+
+		return map[string]map[string]types.PlotValue{"serie": {"avg": value}}, nil
+	}
+
+	scheduler := NewScheduler(&library.Library{Alerts: map[string]*library.Alert{"rule1": rule}}, fetch, 0)
+
+	changes := make([]string, 0)
+
+	scheduler.AddHandler(func(rule *library.Alert, state *State, previous string) {
+		if state.State != previous {
+			changes = append(changes, previous+">"+state.State)
+		}
+	})
+
+	refTime := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, entry := range []struct {
+		offset   time.Duration
+		value    types.PlotValue
+		fail     bool
+		expected string
+	}{
+		// First evaluation applies immediately
+		{0, 5, false, StateOK},
+		// Rule not yet due
+		{30 * time.Second, 20, false, StateOK},
+		// Condition must last for the rule duration
+		{time.Minute, 20, false, StateOK},
+		{2 * time.Minute, 20, false, StateOK},
+		{3 * time.Minute, 20, false, StateCritical},
+		// Fetch errors lead to unknown state
+		{4 * time.Minute, 20, true, StateCritical},
+		{6 * time.Minute, 20, true, StateUnknown},
+	} {
+		value, fail = entry.value, entry.fail
+
+		scheduler.Check(refTime.Add(entry.offset))
+
+		state := scheduler.State("rule1")
+		if state == nil {
+			test.Logf("\nExpected state at %s\nbut got  nil", entry.offset)
+			test.FailNow()
+		}
+
+		if state.State != entry.expected {
+			test.Logf("\nExpected %s at %s\nbut got  %s", entry.expected, entry.offset, state.State)
+			test.Fail()
+		}
+	}
+
+	if expected := "unknown>ok,ok>critical,critical>unknown"; strings.Join(changes, ",") != expected {
+		test.Logf("\nExpected %s\nbut got  %s", expected, strings.Join(changes, ","))
+		test.Fail()
+	}
+
+	// Check disabled rules are not reported
+	rule.Disabled = true
+
+	if states := scheduler.States(); len(states) != 0 {
+		test.Logf("\nExpected no state\nbut got  %d", len(states))
+		test.Fail()
+	}
+}
+
+func Test_SchedulerPanic(test *testing.T) {
+	critical := 10.0
+
+	rule := &library.Alert{Operator: ">=", Critical: &critical, Interval: "1m"}
+	rule.ID, rule.Name = "rule1", "rule1"
+
+	fetch := func(rule *library.Alert, startTime, endTime time.Time) (map[string]map[string]types.PlotValue, error) {
+		panic("fetch panicked")
+	}
+
+	scheduler := NewScheduler(&library.Library{Alerts: map[string]*library.Alert{"rule1": rule}}, fetch, 0)
+
+	refTime := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	scheduler.Check(refTime)
+
+	state := scheduler.State("rule1")
+	if state == nil {
+		test.Logf("\nExpected state\nbut got  nil")
+		test.FailNow()
+	}
+
+	if state.State != StateUnknown || !strings.Contains(state.Message, "fetch panicked") {
+		test.Logf("\nExpected %s state with panic message\nbut got  %s (message: %s)", StateUnknown, state.State,
+			state.Message)
+		test.Fail()
+	}
+
+	// Check rule is scheduled again
+	scheduler.Check(refTime.Add(time.Minute))
+
+	if state = scheduler.State("rule1"); !state.Checked.Equal(refTime.Add(time.Minute)) {
+		test.Logf("\nExpected rule checked at %s\nbut got  %s", refTime.Add(time.Minute), state.Checked)
+		test.Fail()
+	}
+}
@@ -0,0 +1,267 @@
+// Package annotation implements the storage of the events (e.g. deployments, incidents) annotating graphs.
+package annotation
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/facette/facette/pkg/utils"
+	"github.com/facette/facette/thirdparty/github.com/nu7hatch/gouuid"
+)
+
+// Annotation represents an event annotating graphs, either at a single time or over a time range. Annotations can be
+// scoped to an origin and/or a source, empty values matching any of them.
+type Annotation struct {
+	ID       string     `json:"id"`
+	Start    time.Time  `json:"start"`
+	End      *time.Time `json:"end,omitempty"`
+	Title    string     `json:"title"`
+	Text     string     `json:"text"`
+	Tags     []string   `json:"tags"`
+	Origin   string     `json:"origin"`
+	Source   string     `json:"source"`
+	Modified time.Time  `json:"-"`
+}
+
+// Store represents the main structure of an annotations store instance.
+type Store struct {
+	Path        string
+	annotations map[string]*Annotation
+	mutex       sync.RWMutex
+	debugLevel  int
+}
+
+// Validate checks for the annotation validity.
+func (annotation *Annotation) Validate() error {
+	if annotation.Start.IsZero() {
+		return fmt.Errorf("missing start time")
+	} else if annotation.End != nil && annotation.End.Before(annotation.Start) {
+		return fmt.Errorf("end time is before start time")
+	} else if annotation.Title == "" {
+		return fmt.Errorf("missing title")
+	}
+
+	return nil
+}
+
+// Overlaps returns whether the annotation occurs within a given time range, zero times leaving it unbounded.
+func (annotation *Annotation) Overlaps(startTime, endTime time.Time) bool {
+	end := annotation.Start
+	if annotation.End != nil {
+		end = *annotation.End
+	}
+
+	return (startTime.IsZero() || !end.Before(startTime)) && (endTime.IsZero() || !annotation.Start.After(endTime))
+}
+
+// Matches returns whether the annotation scope covers a given origin source.
+func (annotation *Annotation) Matches(origin, source string) bool {
+	return (annotation.Origin == "" || annotation.Origin == origin) &&
+		(annotation.Source == "" || annotation.Source == source)
+}
+
+// HasTags returns whether the annotation has all the given tags or not.
+func (annotation *Annotation) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+
+		for _, annotationTag := range annotation.Tags {
+			if annotationTag == tag {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Refresh updates the store by loading the annotations stored on the filesystem.
+func (store *Store) Refresh() error {
+	annotations := make(map[string]*Annotation)
+
+	walkFunc := func(filePath string, fileInfo os.FileInfo, fileError error) error {
+		if fileInfo == nil || fileInfo.IsDir() || !strings.HasSuffix(filePath, ".json") {
+			return nil
+		}
+
+		annotation := &Annotation{}
+
+		fileInfo, err := utils.JSONLoad(filePath, annotation)
+		if err != nil {
+			return fmt.Errorf("in %s, %s", filePath, err.Error())
+		}
+
+		annotation.Modified = fileInfo.ModTime()
+		annotations[annotation.ID] = annotation
+
+		return nil
+	}
+
+	if _, err := os.Stat(store.Path); err == nil {
+		if err := utils.WalkDir(store.Path, walkFunc); err != nil {
+			log.Println("ERROR: " + err.Error())
+			return err
+		}
+	}
+
+	if store.debugLevel > 0 {
+		log.Printf("DEBUG: loaded %d annotation(s)", len(annotations))
+	}
+
+	store.mutex.Lock()
+	store.annotations = annotations
+	store.mutex.Unlock()
+
+	return nil
+}
+
+// Get returns an existing annotation from the store.
+func (store *Store) Get(id string) (*Annotation, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	annotation, ok := store.annotations[id]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	return annotation, nil
+}
+
+// List returns the annotations occurring within a given time range and having all the given tags, sorted by start
+// time.
+func (store *Store) List(startTime, endTime time.Time, tags []string) []*Annotation {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	result := make(annotationList, 0)
+
+	for _, annotation := range store.annotations {
+		if !annotation.Overlaps(startTime, endTime) || !annotation.HasTags(tags) {
+			continue
+		}
+
+		result = append(result, annotation)
+	}
+
+	sort.Sort(result)
+
+	return result
+}
+
+// Store stores an annotation into the store, assigning it a new identifier if not set.
+func (store *Store) Store(annotation *Annotation) error {
+	if err := annotation.Validate(); err != nil {
+		log.Printf("ERROR: in `%s' annotation, %s", annotation.Title, err)
+		return os.ErrInvalid
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if annotation.ID == "" {
+		uuidTemp, err := uuid.NewV4()
+		if err != nil {
+			return err
+		}
+
+		annotation.ID = uuidTemp.String()
+	} else if _, ok := store.annotations[annotation.ID]; !ok {
+		return os.ErrNotExist
+	}
+
+	annotation.Tags = normalizeTags(annotation.Tags)
+
+	if err := utils.JSONDump(store.getFilePath(annotation.ID), annotation, annotation.Modified); err != nil {
+		return err
+	}
+
+	store.annotations[annotation.ID] = annotation
+
+	return nil
+}
+
+// Delete removes an existing annotation from the store.
+func (store *Store) Delete(id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.annotations[id]; !ok {
+		return os.ErrNotExist
+	}
+
+	if err := syscall.Unlink(store.getFilePath(id)); err != nil {
+		return err
+	}
+
+	delete(store.annotations, id)
+
+	return nil
+}
+
+func (store *Store) getFilePath(id string) string {
+	return path.Join(store.Path, id+".json")
+}
+
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		result = append(result, tag)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// Sort sorts a list of annotations by start time.
+func Sort(annotations []*Annotation) {
+	sort.Sort(annotationList(annotations))
+}
+
+type annotationList []*Annotation
+
+func (list annotationList) Len() int {
+	return len(list)
+}
+
+func (list annotationList) Less(i, j int) bool {
+	return list[i].Start.Before(list[j].Start)
+}
+
+func (list annotationList) Swap(i, j int) {
+	list[i], list[j] = list[j], list[i]
+}
+
+// NewStore creates a new instance of annotations store.
+func NewStore(dirPath string, debugLevel int) *Store {
+	return &Store{
+		Path:        dirPath,
+		annotations: make(map[string]*Annotation),
+		debugLevel:  debugLevel,
+	}
+}
@@ -0,0 +1,114 @@
+package annotation
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_AnnotationOverlaps(test *testing.T) {
+	refTime := time.Date(2014, 1, 1, 12, 0, 0, 0, time.UTC)
+	endTime := refTime.Add(time.Hour)
+
+	point := &Annotation{Start: refTime}
+	span := &Annotation{Start: refTime, End: &endTime}
+
+	for _, entry := range []struct {
+		annotation *Annotation
+		start, end time.Time
+		expected   bool
+	}{
+		{point, refTime.Add(-time.Hour), refTime, true},
+		{point, refTime.Add(time.Minute), refTime.Add(time.Hour), false},
+		{span, refTime.Add(30 * time.Minute), refTime.Add(2 * time.Hour), true},
+		{span, refTime.Add(2 * time.Hour), refTime.Add(3 * time.Hour), false},
+		{span, time.Time{}, refTime.Add(-time.Minute), false},
+		{span, refTime.Add(-time.Minute), time.Time{}, true},
+	} {
+		if result := entry.annotation.Overlaps(entry.start, entry.end); result != entry.expected {
+			test.Logf("\nExpected %v for [%s, %s]\nbut got  %v", entry.expected, entry.start, entry.end, result)
+			test.Fail()
+		}
+	}
+
+	scoped := &Annotation{Origin: "origin1", Source: "host1"}
+
+	if !scoped.Matches("origin1", "host1") || scoped.Matches("origin1", "host2") ||
+		!(&Annotation{}).Matches("origin2", "host2") {
+		test.Logf("\nExpected annotation scope to match origin and source")
+		test.Fail()
+	}
+}
+
+func Test_StoreAnnotations(test *testing.T) {
+	dirPath, err := ioutil.TempDir("", "annotations")
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	defer os.RemoveAll(dirPath)
+
+	store := NewStore(dirPath, 0)
+
+	refTime := time.Date(2014, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, annotation := range []*Annotation{
+		{Start: refTime.Add(time.Hour), Title: "deploy", Tags: []string{"deploy", " app1 ", "deploy"}},
+		{Start: refTime, Title: "incident", Tags: []string{"incident"}},
+	} {
+		annotation.Modified = refTime
+
+		if err = store.Store(annotation); err != nil || annotation.ID == "" {
+			test.Logf("\nExpected annotation #%d to be stored\nbut got  %v", i, err)
+			test.FailNow()
+		}
+	}
+
+	if err = store.Store(&Annotation{Start: refTime}); err != os.ErrInvalid {
+		test.Logf("\nExpected %s\nbut got  %v", os.ErrInvalid, err)
+		test.Fail()
+	}
+
+	if err = store.Store(&Annotation{ID: "unknown", Start: refTime, Title: "unknown"}); err != os.ErrNotExist {
+		test.Logf("\nExpected %s\nbut got  %v", os.ErrNotExist, err)
+		test.Fail()
+	}
+
+	// Check annotations are loaded back from the filesystem
+	store = NewStore(dirPath, 0)
+
+	if err = store.Refresh(); err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	result := store.List(time.Time{}, time.Time{}, nil)
+
+	if len(result) != 2 || result[0].Title != "incident" || result[1].Title != "deploy" {
+		test.Logf("\nExpected incident and deploy annotations\nbut got  %+v", result)
+		test.FailNow()
+	}
+
+	if len(result[1].Tags) != 2 || result[1].Tags[0] != "app1" {
+		test.Logf("\nExpected normalized tags\nbut got  %v", result[1].Tags)
+		test.Fail()
+	}
+
+	if result = store.List(refTime.Add(30*time.Minute), refTime.Add(2*time.Hour), []string{"deploy"}); len(
+		result) != 1 || result[0].Title != "deploy" {
+		test.Logf("\nExpected deploy annotation\nbut got  %+v", result)
+		test.Fail()
+	}
+
+	if err = store.Delete(result[0].ID); err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.Fail()
+	}
+
+	if _, err = store.Get(result[0].ID); err != os.ErrNotExist {
+		test.Logf("\nExpected %s\nbut got  %v", os.ErrNotExist, err)
+		test.Fail()
+	}
+}
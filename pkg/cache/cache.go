@@ -0,0 +1,164 @@
+// Package cache implements an in-memory cache with entries expiration, LRU eviction and requests coalescing.
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSize represents the default maximum number of entries of a cache.
+	DefaultSize = 1000
+)
+
+// Cache represents a cache instance.
+type Cache struct {
+	size      int
+	entries   map[string]*list.Element
+	lru       *list.List
+	inflight  map[string]*call
+	hits      uint64
+	misses    uint64
+	coalesced uint64
+	mutex     sync.Mutex
+}
+
+// Stats represents the cache statistics.
+type Stats struct {
+	Entries   int    `json:"entries"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Coalesced uint64 `json:"coalesced"`
+}
+
+type entry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+type call struct {
+	wait  sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+// NewCache creates a new cache instance holding at most `size' entries.
+func NewCache(size int) *Cache {
+	if size <= 0 {
+		size = DefaultSize
+	}
+
+	return &Cache{
+		size:     size,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		inflight: make(map[string]*call),
+	}
+}
+
+// Get returns the value associated with a key, calling `fetch' to retrieve it if not cached or expired. Concurrent
+// calls for the same key wait for the first one to complete and share its result. Errors are never cached, panics
+// occurring in `fetch' being returned as errors.
+func (cache *Cache) Get(key string, ttl time.Duration, fetch func() (interface{}, error)) (interface{}, error) {
+	cache.mutex.Lock()
+
+	if element, ok := cache.entries[key]; ok {
+		if item := element.Value.(*entry); time.Now().Before(item.expires) {
+			cache.lru.MoveToFront(element)
+			cache.hits++
+			cache.mutex.Unlock()
+
+			return item.value, nil
+		}
+
+		cache.removeElement(element)
+	}
+
+	// Wait for pending call on the same key if any
+	if pending, ok := cache.inflight[key]; ok {
+		cache.coalesced++
+		cache.mutex.Unlock()
+
+		pending.wait.Wait()
+
+		return pending.value, pending.err
+	}
+
+	cache.misses++
+
+	pending := &call{}
+	pending.wait.Add(1)
+	cache.inflight[key] = pending
+
+	cache.mutex.Unlock()
+
+	cache.fetch(key, ttl, pending, fetch)
+
+	return pending.value, pending.err
+}
+
+// Purge removes all the entries from the cache.
+func (cache *Cache) Purge() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.entries = make(map[string]*list.Element)
+	cache.lru.Init()
+}
+
+// Stats returns the cache statistics.
+func (cache *Cache) Stats() Stats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return Stats{
+		Entries:   cache.lru.Len(),
+		Hits:      cache.hits,
+		Misses:    cache.misses,
+		Coalesced: cache.coalesced,
+	}
+}
+
+func (cache *Cache) fetch(key string, ttl time.Duration, pending *call, fetch func() (interface{}, error)) {
+	// Always release the pending call, otherwise waiters on the same key would be blocked forever
+	defer func() {
+		if data := recover(); data != nil {
+			pending.value, pending.err = nil, fmt.Errorf("panic while fetching `%s': %v", key, data)
+		}
+
+		cache.mutex.Lock()
+
+		delete(cache.inflight, key)
+
+		if pending.err == nil && ttl > 0 {
+			cache.set(key, pending.value, ttl)
+		}
+
+		cache.mutex.Unlock()
+
+		pending.wait.Done()
+	}()
+
+	pending.value, pending.err = fetch()
+}
+
+func (cache *Cache) set(key string, value interface{}, ttl time.Duration) {
+	if element, ok := cache.entries[key]; ok {
+		cache.removeElement(element)
+	}
+
+	cache.entries[key] = cache.lru.PushFront(&entry{key: key, value: value, expires: time.Now().Add(ttl)})
+
+	// Evict least recently used entries
+	for cache.lru.Len() > cache.size {
+		cache.removeElement(cache.lru.Back())
+	}
+}
+
+func (cache *Cache) removeElement(element *list.Element) {
+	cache.lru.Remove(element)
+	delete(cache.entries, element.Value.(*entry).key)
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_CacheGet(test *testing.T) {
+	cache := NewCache(10)
+	count := 0
+
+	fetch := func() (interface{}, error) {
+		count++
+		return count, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if value, _ := cache.Get("key", time.Minute, fetch); value != 1 {
+			test.Logf("\nExpected %d\nbut got  %v", 1, value)
+			test.Fail()
+		}
+	}
+
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 1 {
+		test.Logf("\nExpected %+v\nbut got  %+v", Stats{Entries: 1, Hits: 2, Misses: 1}, stats)
+		test.Fail()
+	}
+}
+
+func Test_CacheExpire(test *testing.T) {
+	cache := NewCache(10)
+	count := 0
+
+	fetch := func() (interface{}, error) {
+		count++
+		return count, nil
+	}
+
+	cache.Get("key", time.Millisecond, fetch)
+
+	time.Sleep(5 * time.Millisecond)
+
+	if value, _ := cache.Get("key", time.Millisecond, fetch); value != 2 {
+		test.Logf("\nExpected %d\nbut got  %v", 2, value)
+		test.Fail()
+	}
+}
+
+func Test_CacheError(test *testing.T) {
+	cache := NewCache(10)
+
+	cache.Get("key", time.Minute, func() (interface{}, error) { return nil, fmt.Errorf("failure") })
+
+	if value, err := cache.Get("key", time.Minute, func() (interface{}, error) { return 1, nil }); err != nil ||
+		value != 1 {
+		test.Logf("\nExpected %d\nbut got  %v (error: %v)", 1, value, err)
+		test.Fail()
+	}
+}
+
+func Test_CachePanic(test *testing.T) {
+	cache := NewCache(10)
+
+	if _, err := cache.Get("key", time.Minute, func() (interface{}, error) { panic("failure") }); err == nil {
+		test.Logf("\nExpected error\nbut got  nil")
+		test.Fail()
+	}
+
+	// Pending call must have been released for subsequent calls not to block
+	if value, err := cache.Get("key", time.Minute, func() (interface{}, error) { return 1, nil }); err != nil ||
+		value != 1 {
+		test.Logf("\nExpected %d\nbut got  %v (error: %v)", 1, value, err)
+		test.Fail()
+	}
+}
+
+func Test_CacheEvict(test *testing.T) {
+	cache := NewCache(2)
+
+	for _, key := range []string{"a", "b", "a", "c"} {
+		cache.Get(key, time.Minute, func() (interface{}, error) { return key, nil })
+	}
+
+	// `b' is the least recently used entry and must have been evicted
+	for key, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := cache.entries[key]; ok != expected {
+			test.Logf("\nExpected `%s' presence to be %v\nbut got  %v", key, expected, ok)
+			test.Fail()
+		}
+	}
+}
+
+func Test_CacheCoalesce(test *testing.T) {
+	cache := NewCache(10)
+	count := 0
+	release := make(chan bool)
+
+	fetch := func() (interface{}, error) {
+		count++
+		<-release
+		return count, nil
+	}
+
+	wait := &sync.WaitGroup{}
+	results := make(chan interface{}, 5)
+
+	for i := 0; i < 5; i++ {
+		wait.Add(1)
+
+		go func() {
+			defer wait.Done()
+
+			value, _ := cache.Get("key", 0, fetch)
+			results <- value
+		}()
+	}
+
+	// Leave goroutines time to join the pending call
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wait.Wait()
+	close(results)
+
+	for value := range results {
+		if value != 1 {
+			test.Logf("\nExpected %d\nbut got  %v", 1, value)
+			test.Fail()
+		}
+	}
+
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 1 || stats.Coalesced != 4 {
+		test.Logf("\nExpected %+v\nbut got  %+v", Stats{Misses: 1, Coalesced: 4}, stats)
+		test.Fail()
+	}
+}
@@ -20,17 +20,20 @@ const (
 
 // Config represents the main of the service configuration system.
 type Config struct {
-	Path      string                   `json:"-"`
-	BindAddr  string                   `json:"bind"`
-	BaseDir   string                   `json:"base_dir"`
-	DataDir   string                   `json:"data_dir"`
-	OriginDir string                   `json:"origin_dir"`
-	PidFile   string                   `json:"pid_file"`
-	ServerLog string                   `json:"server_log"`
-	URLPrefix string                   `json:"url_prefix"`
-	Auth      map[string]string        `json:"auth"`
-	Scales    [][2]interface{}         `json:"scales"`
-	Origins   map[string]*OriginConfig `json:"-"`
+	Path        string                   `json:"-"`
+	BindAddr    string                   `json:"bind"`
+	BaseDir     string                   `json:"base_dir"`
+	DataDir     string                   `json:"data_dir"`
+	OriginDir   string                   `json:"origin_dir"`
+	PidFile     string                   `json:"pid_file"`
+	ServerLog   string                   `json:"server_log"`
+	URLPrefix   string                   `json:"url_prefix"`
+	Auth        map[string]string        `json:"auth"`
+	Scales      [][2]interface{}         `json:"scales"`
+	CacheSize   int                      `json:"cache_size"`
+	EmbedTokens []string                 `json:"embed_tokens"`
+	Notify      *NotifyConfig            `json:"notify"`
+	Origins     map[string]*OriginConfig `json:"-"`
 }
 
 // Load loads the configuration from the filesystem.
@@ -0,0 +1,24 @@
+package config
+
+// NotifyConfig represents the alerts notifications entry in the configuration system.
+type NotifyConfig struct {
+	Channels    map[string]*ChannelConfig `json:"channels"`
+	FlapWindow  string                    `json:"flap_window"`
+	FlapChanges int                       `json:"flap_changes"`
+	HistorySize int                       `json:"history_size"`
+}
+
+// ChannelConfig represents a notification channel entry in a NotifyConfig instance.
+type ChannelConfig struct {
+	Type     string            `json:"type"`
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers"`
+	Host     string            `json:"host"`
+	Username string            `json:"username"`
+	Password string            `json:"password"`
+	From     string            `json:"from"`
+	To       []string          `json:"to"`
+	Subject  string            `json:"subject"`
+	Template string            `json:"template"`
+	Timeout  int               `json:"timeout"`
+}
@@ -10,6 +10,7 @@ type OriginConfig struct {
 	Connector map[string]string          `json:"connector"`
 	Filters   []*OriginFilterConfig      `json:"filters"`
 	Templates map[string]*TemplateConfig `json:"templates"`
+	CacheTTL  int                        `json:"cache_ttl"`
 	Modified  time.Time                  `json:"-"`
 }
 
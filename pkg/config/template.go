@@ -10,6 +10,7 @@ type TemplateConfig struct {
 	StackMode    int                    `json:"stack_mode"`
 	Stacks       []*TemplateStackConfig `json:"stacks"`
 	Options      map[string]string      `json:"options"`
+	Embeddable   bool                   `json:"embeddable"`
 	SplitRegexp  *regexp.Regexp         `json:"-"`
 }
 
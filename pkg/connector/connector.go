@@ -14,6 +14,8 @@ const (
 	OperGroupTypeAvg
 	// OperGroupTypeSum represents a SUM operation group mode.
 	OperGroupTypeSum
+	// OperGroupTypeExpression represents an arithmetic expression operation group mode, evaluated server-side.
+	OperGroupTypeExpression
 )
 
 var (
@@ -28,6 +30,11 @@ type Connector interface {
 	Refresh() error
 }
 
+// EventsConnector represents the interface of connector handlers also providing events annotating plots.
+type EventsConnector interface {
+	GetEvents(startTime, endTime time.Time) ([]*Event, error)
+}
+
 // MetricQuery represents a metric entry in a SerieQuery.
 type MetricQuery struct {
 	Name       string
@@ -54,3 +61,12 @@ type PlotResult struct {
 	Plots []types.PlotValue
 	Info  map[string]types.PlotValue
 }
+
+// Event represents an event returned by an events connector.
+type Event struct {
+	ID    string
+	Time  time.Time
+	Title string
+	Text  string
+	Tags  []string
+}
@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,19 +16,30 @@ import (
 )
 
 const (
+	graphiteURLEvents  string = "/events/get_data"
 	graphiteURLMetrics string = "/metrics/index.json"
 	graphiteURLRender  string = "/render"
 )
 
 type graphitePlot struct {
 	Target     string
-	Datapoints [][2]float64
+	Datapoints [][2]*float64
+}
+
+type graphiteEvent struct {
+	ID   interface{} `json:"id"`
+	When float64     `json:"when"`
+	What string      `json:"what"`
+	Data string      `json:"data"`
+	Tags interface{} `json:"tags"`
 }
 
 // GraphiteConnector represents the main structure of the Graphite connector.
 type GraphiteConnector struct {
 	URL         string
 	InsecureTLS bool
+	Events      bool
+	EventsTags  []string
 	inputChan   *chan [2]string
 }
 
@@ -73,6 +87,79 @@ func (handler *GraphiteConnector) GetPlots(query *GroupQuery, startTime, endTime
 	return result, nil
 }
 
+// GetEvents returns the events stored using the Graphite events API within a time interval, restricted to the
+// configured tags if any.
+func (handler *GraphiteConnector) GetEvents(startTime, endTime time.Time) ([]*Event, error) {
+	if !handler.Events {
+		return nil, nil
+	}
+
+	httpTransport := &http.Transport{}
+	if handler.InsecureTLS {
+		httpTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	httpClient := http.Client{Transport: httpTransport}
+
+	queryURL := fmt.Sprintf("%s%s?from=%d&until=%d", strings.TrimSuffix(handler.URL, "/"), graphiteURLEvents,
+		startTime.Unix(), endTime.Unix())
+
+	if len(handler.EventsTags) > 0 {
+		queryURL += "&tags=" + url.QueryEscape(strings.Join(handler.EventsTags, " "))
+	}
+
+	response, err := httpClient.Get(queryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if err = graphiteCheckConnectorResponse(response); err != nil {
+		return nil, fmt.Errorf("invalid HTTP backend response: %s", err)
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read HTTP response body: %s", err)
+	}
+
+	graphiteEvents := make([]graphiteEvent, 0)
+	if err = json.Unmarshal(data, &graphiteEvents); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal JSON data: %s", err)
+	}
+
+	result := make([]*Event, 0)
+
+	for _, graphiteEvent := range graphiteEvents {
+		event := &Event{
+			Time:  time.Unix(0, int64(graphiteEvent.When*float64(time.Second))),
+			Title: graphiteEvent.What,
+			Text:  graphiteEvent.Data,
+			Tags:  make([]string, 0),
+		}
+
+		if id, ok := graphiteEvent.ID.(float64); ok {
+			event.ID = strconv.FormatFloat(id, 'f', -1, 64)
+		}
+
+		// Handle both space-separated and list tags formats depending on Graphite versions
+		switch tags := graphiteEvent.Tags.(type) {
+		case string:
+			event.Tags = strings.Fields(tags)
+
+		case []interface{}:
+			for _, tag := range tags {
+				event.Tags = append(event.Tags, fmt.Sprintf("%v", tag))
+			}
+		}
+
+		result = append(result, event)
+	}
+
+	return result, nil
+}
+
 // Refresh triggers a full connector data update.
 func (handler *GraphiteConnector) Refresh() error {
 	httpTransport := &http.Transport{}
@@ -189,7 +276,8 @@ func graphiteBuildQueryURL(query *GroupQuery, startTime, endTime time.Time) (str
 }
 
 func graphiteExtractPlotResult(plots []graphitePlot) (*PlotResult, error) {
-	var min, max, avg, last float64
+	// Keep missing information values (i.e. `None') as NaN
+	min, max, avg, last := math.NaN(), math.NaN(), math.NaN(), math.NaN()
 
 	result := &PlotResult{Info: make(map[string]types.PlotValue)}
 
@@ -198,8 +286,13 @@ func graphiteExtractPlotResult(plots []graphitePlot) (*PlotResult, error) {
 		return result, nil
 	}
 
+	// Report null datapoints as missing values
 	for _, plotPoint := range plots[0].Datapoints {
-		result.Plots = append(result.Plots, types.PlotValue(plotPoint[0]))
+		if plotPoint[0] == nil {
+			result.Plots = append(result.Plots, types.PlotValue(math.NaN()))
+		} else {
+			result.Plots = append(result.Plots, types.PlotValue(*plotPoint[0]))
+		}
 	}
 
 	// Scan the target legend for plot min/max/avg/last info
@@ -230,6 +323,11 @@ func init() {
 			connector.InsecureTLS = true
 		}
 
+		if config["events"] == "yes" {
+			connector.Events = true
+			connector.EventsTags = strings.Fields(config["events_tags"])
+		}
+
 		return connector, nil
 	}
 }
@@ -0,0 +1,71 @@
+package connector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_GraphiteGetEvents(test *testing.T) {
+	var query string
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		query = request.URL.RawQuery
+
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Write([]byte(`[
+			{"id": 1, "when": 1388577600.0, "what": "deploy app1", "data": "v1.2", "tags": "deploy app1"},
+			{"id": 2, "when": 1388581200.5, "what": "deploy app2", "data": "", "tags": ["deploy", "app2"]}
+		]`))
+	}))
+	defer server.Close()
+
+	handler, err := Connectors["graphite"](nil, map[string]string{
+		"url":         server.URL,
+		"events":      "yes",
+		"events_tags": "deploy",
+	})
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	startTime := time.Date(2014, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	events, err := handler.(EventsConnector).GetEvents(startTime, startTime.Add(2*time.Hour))
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	if expected := "from=1388577600&until=1388584800&tags=deploy"; query != expected {
+		test.Logf("\nExpected %s\nbut got  %s", expected, query)
+		test.Fail()
+	}
+
+	if len(events) != 2 {
+		test.Logf("\nExpected 2 events\nbut got  %d", len(events))
+		test.FailNow()
+	}
+
+	if events[0].ID != "1" || !events[0].Time.Equal(startTime) || events[0].Title != "deploy app1" ||
+		events[0].Text != "v1.2" || strings.Join(events[0].Tags, ",") != "deploy,app1" {
+		test.Logf("\nExpected first event to be parsed\nbut got  %+v", events[0])
+		test.Fail()
+	}
+
+	if strings.Join(events[1].Tags, ",") != "deploy,app2" {
+		test.Logf("\nExpected list tags to be parsed\nbut got  %v", events[1].Tags)
+		test.Fail()
+	}
+
+	// Check events are not fetched unless enabled
+	handler, _ = Connectors["graphite"](nil, map[string]string{"url": server.URL})
+
+	if events, err = handler.(EventsConnector).GetEvents(startTime, startTime); err != nil || events != nil {
+		test.Logf("\nExpected no events\nbut got  %v (%v)", events, err)
+		test.Fail()
+	}
+}
@@ -0,0 +1,142 @@
+package library
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/facette/facette/pkg/utils"
+)
+
+const (
+	// DefaultAlertStatistic represents the default information statistic alert rules are evaluated on.
+	DefaultAlertStatistic = "avg"
+	// DefaultAlertWindow represents the default time window alert rules statistics are computed over.
+	DefaultAlertWindow = "5m"
+	// DefaultAlertInterval represents the default interval between alert rules evaluations.
+	DefaultAlertInterval = "1m"
+)
+
+// Alert represents an alert rule item.
+type Alert struct {
+	Item
+	Graph      string            `json:"graph"`
+	Attributes map[string]string `json:"attributes"`
+	Origin     string            `json:"origin"`
+	Source     string            `json:"source"`
+	Metric     string            `json:"metric"`
+	Serie      string            `json:"serie"`
+	Statistic  string            `json:"statistic"`
+	Window     string            `json:"window"`
+	Operator   string            `json:"operator"`
+	Warning    *float64          `json:"warning"`
+	Critical   *float64          `json:"critical"`
+	Duration   string            `json:"duration"`
+	Interval   string            `json:"interval"`
+	Notify     []string          `json:"notify"`
+	Repeat     string            `json:"repeat"`
+	Disabled   bool              `json:"disabled"`
+}
+
+// GetAlerts returns a copy of the library alert rules map, safe to range over while the library is being updated.
+func (library *Library) GetAlerts() map[string]*Alert {
+	library.alertsMutex.RLock()
+	defer library.alertsMutex.RUnlock()
+
+	result := make(map[string]*Alert, len(library.Alerts))
+	for id, alert := range library.Alerts {
+		result[id] = alert
+	}
+
+	return result
+}
+
+// GetStatistic returns the information statistic the alert rule is evaluated on.
+func (alert *Alert) GetStatistic() string {
+	if alert.Statistic == "" {
+		return DefaultAlertStatistic
+	}
+
+	return alert.Statistic
+}
+
+// GetDurations returns the statistics window, the duration conditions must last for and the evaluation interval of
+// the alert rule.
+func (alert *Alert) GetDurations() (time.Duration, time.Duration, time.Duration, error) {
+	result := make([]time.Duration, 3)
+
+	for i, entry := range [][2]string{
+		{alert.Window, DefaultAlertWindow},
+		{alert.Duration, "0s"},
+		{alert.Interval, DefaultAlertInterval},
+	} {
+		if entry[0] == "" {
+			entry[0] = entry[1]
+		}
+
+		refTime := time.Now()
+
+		value, err := utils.TimeApplyRange(refTime, entry[0])
+		if err != nil || value.Before(refTime) {
+			return 0, 0, 0, fmt.Errorf("invalid `%s' range", entry[0])
+		}
+
+		result[i] = value.Sub(refTime)
+	}
+
+	return result[0], result[1], result[2], nil
+}
+
+// GetRepeat returns the interval notifications are repeated at while the alert rule is not in ok state, zero meaning
+// no repetition.
+func (alert *Alert) GetRepeat() (time.Duration, error) {
+	if alert.Repeat == "" {
+		return 0, nil
+	}
+
+	refTime := time.Now()
+
+	value, err := utils.TimeApplyRange(refTime, alert.Repeat)
+	if err != nil || value.Before(refTime) {
+		return 0, fmt.Errorf("invalid `%s' repeat interval", alert.Repeat)
+	}
+
+	return value.Sub(refTime), nil
+}
+
+func (library *Library) validateAlert(alert *Alert) error {
+	if alert.Graph != "" && (alert.Origin != "" || alert.Source != "" || alert.Metric != "") {
+		return fmt.Errorf("either a graph or a metric must be referenced")
+	} else if alert.Graph == "" && (alert.Origin == "" || alert.Source == "" || alert.Metric == "") {
+		return fmt.Errorf("missing graph or metric reference")
+	} else if alert.Graph != "" && !library.ItemExists(alert.Graph, LibraryItemGraph) {
+		return fmt.Errorf("unknown `%s' graph", alert.Graph)
+	}
+
+	switch alert.Operator {
+	case ">", ">=", "<", "<=", "==", "!=":
+	default:
+		return fmt.Errorf("unknown `%s' comparison operator", alert.Operator)
+	}
+
+	if alert.Warning == nil && alert.Critical == nil {
+		return fmt.Errorf("missing warning or critical threshold")
+	}
+
+	if window, _, interval, err := alert.GetDurations(); err != nil {
+		return err
+	} else if window <= 0 || interval <= 0 {
+		return fmt.Errorf("window and interval must be greater than zero")
+	}
+
+	if _, err := alert.GetRepeat(); err != nil {
+		return err
+	}
+
+	for _, name := range alert.Notify {
+		if library.Config.Notify == nil || library.Config.Notify.Channels[name] == nil {
+			return fmt.Errorf("unknown `%s' notification channel", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,463 @@
+package library
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// ImportStrategyNone represents a bundle import failing on the first name conflict.
+	ImportStrategyNone = iota
+	// ImportStrategySkip represents a bundle import keeping existing items on name conflicts.
+	ImportStrategySkip
+	// ImportStrategyOverwrite represents a bundle import replacing existing items on name conflicts.
+	ImportStrategyOverwrite
+	// ImportStrategyRename represents a bundle import renaming imported items on name conflicts.
+	ImportStrategyRename
+)
+
+var importStrategies = map[string]int{
+	"":          ImportStrategyNone,
+	"none":      ImportStrategyNone,
+	"skip":      ImportStrategySkip,
+	"overwrite": ImportStrategyOverwrite,
+	"rename":    ImportStrategyRename,
+}
+
+// Bundle represents a portable set of library items along with their dependencies.
+type Bundle struct {
+	SourceGroups []*Group      `json:"sourcegroups"`
+	MetricGroups []*Group      `json:"metricgroups"`
+	Graphs       []*Graph      `json:"graphs"`
+	Collections  []*Collection `json:"collections"`
+}
+
+// BundleReportEntry represents the outcome of an item import.
+type BundleReportEntry struct {
+	Type   string `json:"type"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+}
+
+// ParseImportStrategy returns the import strategy matching a given name.
+func ParseImportStrategy(name string) (int, error) {
+	strategy, ok := importStrategies[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown `%s' import strategy", name)
+	}
+
+	return strategy, nil
+}
+
+// ExportCollection exports a collection along with its children, graphs and groups into a bundle.
+func (library *Library) ExportCollection(id string) (*Bundle, error) {
+	var collection *Collection
+
+	if !library.ItemExists(id, LibraryItemCollection) {
+		return nil, os.ErrNotExist
+	}
+
+	bundle := &Bundle{}
+
+	graphs := make(map[string]bool)
+	groups := make(map[int]map[string]bool)
+	groups[LibraryItemSourceGroup] = make(map[string]bool)
+	groups[LibraryItemMetricGroup] = make(map[string]bool)
+
+	// Browse collections tree
+	collectionStack := []*Collection{library.Collections[id]}
+
+	for len(collectionStack) > 0 {
+		collection, collectionStack = collectionStack[0], collectionStack[1:]
+		collectionStack = append(collectionStack, collection.Children...)
+
+		// Detach root collection from its parent as it might not exist on import
+		if collection.ID == id && collection.ParentID != "" {
+			collectionTemp := &Collection{}
+			*collectionTemp = *collection
+			collectionTemp.ParentID = ""
+
+			bundle.Collections = append(bundle.Collections, collectionTemp)
+		} else {
+			bundle.Collections = append(bundle.Collections, collection)
+		}
+
+		graphIDs := make([]string, 0)
+
+		for _, entry := range collection.Entries {
+			graphIDs = append(graphIDs, entry.ID)
+		}
+
+		if collection.Type == CollectionTypeDynamic {
+			graphIDs = append(graphIDs, collection.Templates...)
+
+			if item, err := library.GetItemByName(collection.Group, LibraryItemSourceGroup); err == nil &&
+				!groups[LibraryItemSourceGroup][collection.Group] {
+				groups[LibraryItemSourceGroup][collection.Group] = true
+				bundle.SourceGroups = append(bundle.SourceGroups, item.(*Group))
+			}
+		}
+
+		for _, graphID := range graphIDs {
+			if _, ok := library.Graphs[graphID]; !ok || graphs[graphID] {
+				continue
+			}
+
+			graphs[graphID] = true
+			bundle.Graphs = append(bundle.Graphs, library.Graphs[graphID])
+		}
+	}
+
+	// Retrieve groups referenced by graphs series
+	for _, graph := range bundle.Graphs {
+		for _, serie := range graph.series() {
+			for groupType, groupName := range serie.groupNames() {
+				if groups[groupType][groupName] {
+					continue
+				}
+
+				item, err := library.GetItemByName(groupName, groupType)
+				if err != nil {
+					continue
+				}
+
+				groups[groupType][groupName] = true
+
+				if groupType == LibraryItemSourceGroup {
+					bundle.SourceGroups = append(bundle.SourceGroups, item.(*Group))
+				} else {
+					bundle.MetricGroups = append(bundle.MetricGroups, item.(*Group))
+				}
+			}
+		}
+	}
+
+	return bundle, nil
+}
+
+// ImportBundle imports bundle items into the library, handling names conflicts according to the given strategy.
+// Import stops on the first failing item, the returned report then listing the items already imported.
+func (library *Library) ImportBundle(bundle *Bundle, strategy int, preserveIDs bool) ([]*BundleReportEntry, error) {
+	report := make([]*BundleReportEntry, 0)
+
+	// Check for names conflicts prior to any modification
+	if strategy == ImportStrategyNone {
+		for itemType, items := range bundle.items() {
+			for _, item := range items {
+				if _, err := library.GetItemByName(item.Name, itemType); err == nil {
+					return nil, os.ErrExist
+				}
+			}
+		}
+	}
+
+	// Import groups
+	groupNames := make(map[int]map[string]string)
+
+	for _, groupType := range []int{LibraryItemSourceGroup, LibraryItemMetricGroup} {
+		groupNames[groupType] = make(map[string]string)
+
+		groups := bundle.SourceGroups
+		if groupType == LibraryItemMetricGroup {
+			groups = bundle.MetricGroups
+		}
+
+		for _, group := range groups {
+			name := group.Name
+			group.Type = groupType
+
+			entry, err := library.importItem(group, groupType, strategy, preserveIDs)
+			if err != nil {
+				return report, err
+			}
+
+			groupNames[groupType][name] = entry.Name
+			report = append(report, entry)
+		}
+	}
+
+	// Import graphs
+	graphIDs := make(map[string]string)
+
+	for _, graph := range bundle.Graphs {
+		id := graph.ID
+
+		// Follow renamed groups
+		for _, serie := range graph.series() {
+			for groupType, groupName := range serie.groupNames() {
+				if newName, ok := groupNames[groupType][groupName]; ok {
+					serie.setGroupName(groupType, newName)
+				}
+			}
+		}
+
+		graph.Volatile = false
+
+		entry, err := library.importItem(graph, LibraryItemGraph, strategy, preserveIDs)
+		if err != nil {
+			return report, err
+		}
+
+		graphIDs[id] = entry.ID
+		report = append(report, entry)
+	}
+
+	// Import collections (parents first)
+	collectionIDs := make(map[string]string)
+
+	bundleIDs := make(map[string]bool)
+	for _, collection := range bundle.Collections {
+		bundleIDs[collection.ID] = true
+	}
+
+	pending := bundle.Collections
+
+	for len(pending) > 0 {
+		next := make([]*Collection, 0)
+
+		for _, collection := range pending {
+			id := collection.ID
+
+			if collection.ParentID != "" && bundleIDs[collection.ParentID] {
+				if _, ok := collectionIDs[collection.ParentID]; !ok {
+					next = append(next, collection)
+					continue
+				}
+
+				collection.ParentID = collectionIDs[collection.ParentID]
+			} else if !library.ItemExists(collection.ParentID, LibraryItemCollection) {
+				collection.ParentID = ""
+			}
+
+			for _, collectionEntry := range collection.Entries {
+				if graphID, ok := graphIDs[collectionEntry.ID]; ok {
+					collectionEntry.ID = graphID
+				}
+			}
+
+			for i, templateID := range collection.Templates {
+				if graphID, ok := graphIDs[templateID]; ok {
+					collection.Templates[i] = graphID
+				}
+			}
+
+			if newName, ok := groupNames[LibraryItemSourceGroup][collection.Group]; ok {
+				collection.Group = newName
+			}
+
+			collection.Parent = nil
+			collection.Children = nil
+
+			entry, err := library.importItem(collection, LibraryItemCollection, strategy, preserveIDs)
+			if err != nil {
+				return report, err
+			}
+
+			collectionIDs[id] = entry.ID
+			report = append(report, entry)
+		}
+
+		if len(next) == len(pending) {
+			return report, fmt.Errorf("circular collections parent relations")
+		}
+
+		pending = next
+	}
+
+	library.linkCollections()
+
+	return report, nil
+}
+
+func (library *Library) importItem(item interface{}, itemType int, strategy int,
+	preserveIDs bool) (*BundleReportEntry, error) {
+
+	itemStruct := getItemStruct(item, itemType)
+
+	entry := &BundleReportEntry{Type: getItemDirName(itemType), Action: "imported"}
+
+	if itemTemp, err := library.GetItemByName(itemStruct.Name, itemType); err == nil {
+		existing := getItemStruct(itemTemp, itemType)
+
+		switch strategy {
+		case ImportStrategySkip:
+			entry.ID, entry.Name, entry.Action = existing.ID, existing.Name, "skipped"
+			return entry, nil
+
+		case ImportStrategyOverwrite:
+			itemStruct.ID = existing.ID
+			entry.Action = "overwritten"
+
+		case ImportStrategyRename:
+			itemStruct.ID = ""
+			itemStruct.Name = library.getUniqueName(itemStruct.Name, itemType)
+			entry.Action = "renamed"
+
+		default:
+			return nil, os.ErrExist
+		}
+	} else if !preserveIDs || !library.idRegexp.MatchString(itemStruct.ID) {
+		itemStruct.ID = ""
+	} else if library.ItemExists(itemStruct.ID, itemType) {
+		// Identifier is already used by an item having a different name
+		itemStruct.ID = ""
+	}
+
+	itemStruct.Modified = time.Now()
+
+	if err := library.storeItem(item, itemType, true); err != nil {
+		return nil, err
+	}
+
+	entry.ID, entry.Name = itemStruct.ID, itemStruct.Name
+
+	return entry, nil
+}
+
+func (library *Library) getUniqueName(name string, itemType int) string {
+	for i := 1; ; i++ {
+		newName := fmt.Sprintf("%s (%d)", name, i)
+
+		if _, err := library.GetItemByName(newName, itemType); err != nil {
+			return newName
+		}
+	}
+}
+
+// WriteTar writes the bundle items as JSON files into a tar archive.
+func (bundle *Bundle) WriteTar(writer io.Writer) error {
+	archive := tar.NewWriter(writer)
+
+	for itemType, items := range bundle.items() {
+		for _, item := range items {
+			var data interface{}
+
+			switch itemType {
+			case LibraryItemSourceGroup, LibraryItemMetricGroup:
+				data = item.group
+			case LibraryItemGraph:
+				data = item.graph
+			case LibraryItemCollection:
+				data = item.collection
+			}
+
+			output, err := json.MarshalIndent(data, "", "    ")
+			if err != nil {
+				return err
+			}
+
+			if err := archive.WriteHeader(&tar.Header{
+				Name:    path.Join(getItemDirName(itemType), item.ID+".json"),
+				Mode:    0644,
+				Size:    int64(len(output)),
+				ModTime: time.Now(),
+			}); err != nil {
+				return err
+			}
+
+			if _, err := archive.Write(output); err != nil {
+				return err
+			}
+		}
+	}
+
+	return archive.Close()
+}
+
+// ReadBundleTar reads a bundle from a tar archive.
+func ReadBundleTar(reader io.Reader) (*Bundle, error) {
+	bundle := &Bundle{}
+
+	archive := tar.NewReader(reader)
+
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(header.Name, ".json") {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(archive)
+		if err != nil {
+			return nil, err
+		}
+
+		dirName, _ := path.Split(path.Clean(header.Name))
+
+		switch strings.Trim(dirName, "/") {
+		case getItemDirName(LibraryItemSourceGroup):
+			group := &Group{}
+			err = json.Unmarshal(data, group)
+			bundle.SourceGroups = append(bundle.SourceGroups, group)
+
+		case getItemDirName(LibraryItemMetricGroup):
+			group := &Group{}
+			err = json.Unmarshal(data, group)
+			bundle.MetricGroups = append(bundle.MetricGroups, group)
+
+		case getItemDirName(LibraryItemGraph):
+			graph := &Graph{}
+			err = json.Unmarshal(data, graph)
+			bundle.Graphs = append(bundle.Graphs, graph)
+
+		case getItemDirName(LibraryItemCollection):
+			collection := &Collection{}
+			err = json.Unmarshal(data, collection)
+			bundle.Collections = append(bundle.Collections, collection)
+
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("in %s, %s", header.Name, err.Error())
+		}
+	}
+
+	return bundle, nil
+}
+
+type bundleItem struct {
+	*Item
+	group      *Group
+	graph      *Graph
+	collection *Collection
+}
+
+func (bundle *Bundle) items() map[int][]*bundleItem {
+	result := make(map[int][]*bundleItem)
+
+	for _, group := range bundle.SourceGroups {
+		result[LibraryItemSourceGroup] = append(result[LibraryItemSourceGroup],
+			&bundleItem{Item: &group.Item, group: group})
+	}
+
+	for _, group := range bundle.MetricGroups {
+		result[LibraryItemMetricGroup] = append(result[LibraryItemMetricGroup],
+			&bundleItem{Item: &group.Item, group: group})
+	}
+
+	for _, graph := range bundle.Graphs {
+		result[LibraryItemGraph] = append(result[LibraryItemGraph], &bundleItem{Item: &graph.Item, graph: graph})
+	}
+
+	for _, collection := range bundle.Collections {
+		result[LibraryItemCollection] = append(result[LibraryItemCollection],
+			&bundleItem{Item: &collection.Item, collection: collection})
+	}
+
+	return result
+}
@@ -2,28 +2,68 @@ package library
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/facette/facette/pkg/utils"
 	"github.com/facette/facette/thirdparty/github.com/fatih/set"
 )
 
+const (
+	// CollectionTypeStatic represents a collection type having user-defined entries only.
+	CollectionTypeStatic = iota
+	// CollectionTypeDynamic represents a collection type having entries generated from a source group.
+	CollectionTypeDynamic
+)
+
 // Collection represents a collection of graphs.
 type Collection struct {
 	Item
-	Entries  []*CollectionEntry `json:"entries"`
-	Parent   *Collection        `json:"-"`
-	ParentID string             `json:"parent"`
-	Children []*Collection      `json:"-"`
+	Type      int                `json:"type"`
+	Entries   []*CollectionEntry `json:"entries"`
+	Group     string             `json:"group"`
+	Templates []string           `json:"templates"`
+	Parent    *Collection        `json:"-"`
+	ParentID  string             `json:"parent"`
+	Children  []*Collection      `json:"-"`
 }
 
 // CollectionEntry represents a collection entry.
 type CollectionEntry struct {
-	ID      string            `json:"id"`
-	Options map[string]string `json:"options"`
+	ID         string            `json:"id"`
+	Options    map[string]string `json:"options"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// ExpandCollection returns a dynamic collection along with the entries generated by crossing its source group
+// expansion with its graph templates. Generated entries are evaluated again once the catalog is refreshed.
+func (library *Library) ExpandCollection(collection *Collection) *Collection {
+	if collection.Type != CollectionTypeDynamic {
+		return collection
+	}
+
+	library.expandMutex.Lock()
+	defer library.expandMutex.Unlock()
+
+	if library.expandTime.Before(library.Catalog.Updated) || library.expandedEntries == nil {
+		library.expandedEntries = make(map[string][]*CollectionEntry)
+		library.expandTime = time.Now()
+	}
+
+	if _, ok := library.expandedEntries[collection.ID]; !ok {
+		library.expandedEntries[collection.ID] = library.getCollectionDynamicEntries(collection)
+	}
+
+	collectionTemp := &Collection{}
+	*collectionTemp = *collection
+	collectionTemp.Entries = append(append([]*CollectionEntry{}, collection.Entries...),
+		library.expandedEntries[collection.ID]...)
+
+	return collectionTemp
 }
 
 // FilterCollection filters collection entries by graphs titles.
@@ -193,3 +233,42 @@ func (library *Library) GetCollectionTemplate(name string) (*Collection, error)
 
 	return collection, nil
 }
+
+func (library *Library) getCollectionDynamicEntries(collection *Collection) []*CollectionEntry {
+	entries := make([]*CollectionEntry, 0)
+
+	sources := library.ExpandGroup(collection.Group, LibraryItemSourceGroup)
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		for _, templateID := range collection.Templates {
+			template, ok := library.Graphs[templateID]
+			if !ok || !template.Template {
+				log.Printf("ERROR: unknown `%s' graph template", templateID)
+				continue
+			}
+
+			attributes := map[string]string{"source": source}
+
+			graph, err := library.InstantiateGraph(template, attributes)
+			if err != nil {
+				log.Println("ERROR: " + err.Error())
+				continue
+			}
+
+			entries = append(entries, &CollectionEntry{
+				ID:         templateID,
+				Options:    map[string]string{"title": graph.Name},
+				Attributes: attributes,
+			})
+		}
+	}
+
+	return entries
+}
+
+func (library *Library) resetExpandedEntries() {
+	library.expandMutex.Lock()
+	library.expandedEntries = nil
+	library.expandMutex.Unlock()
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/facette/facette/pkg/plot"
 	"github.com/facette/facette/thirdparty/github.com/nu7hatch/gouuid"
 )
 
@@ -27,10 +28,12 @@ const (
 // Graph represents a graph containing list of series.
 type Graph struct {
 	Item
-	Type      int      `json:"type"`
-	StackMode int      `json:"stack_mode"`
-	Stacks    []*Stack `json:"stacks"`
-	Volatile  bool     `json:"-"`
+	Type       int      `json:"type"`
+	StackMode  int      `json:"stack_mode"`
+	Stacks     []*Stack `json:"stacks"`
+	Template   bool     `json:"template"`
+	Embeddable bool     `json:"embeddable"`
+	Volatile   bool     `json:"-"`
 }
 
 // Stack represents a set of operation group entries.
@@ -41,20 +44,29 @@ type Stack struct {
 
 // OperGroup represents an operation group entry.
 type OperGroup struct {
-	Name    string                 `json:"name"`
-	Type    int                    `json:"type"`
-	Series  []*Serie               `json:"series"`
-	Scale   float64                `json:"scale"`
-	Options map[string]interface{} `json:"options"`
+	Name       string                 `json:"name"`
+	Type       int                    `json:"type"`
+	Series     []*Serie               `json:"series"`
+	Scale      float64                `json:"scale"`
+	Expression string                 `json:"expression"`
+	Fill       string                 `json:"fill"`
+	Transforms []*plot.Transform      `json:"transforms"`
+	Forecast   *plot.Forecast         `json:"forecast"`
+	Anomaly    *plot.Anomaly          `json:"anomaly"`
+	Options    map[string]interface{} `json:"options"`
 }
 
 // Serie represents a serie entry.
 type Serie struct {
-	Name   string  `json:"name"`
-	Origin string  `json:"origin"`
-	Source string  `json:"source"`
-	Metric string  `json:"metric"`
-	Scale  float64 `json:"scale"`
+	Name       string            `json:"name"`
+	Origin     string            `json:"origin"`
+	Source     string            `json:"source"`
+	Metric     string            `json:"metric"`
+	Scale      float64           `json:"scale"`
+	Fill       string            `json:"fill"`
+	Transforms []*plot.Transform `json:"transforms"`
+	Forecast   *plot.Forecast    `json:"forecast"`
+	Anomaly    *plot.Anomaly     `json:"anomaly"`
 }
 
 // GetGraphMetric gets a graph metric item.
@@ -97,8 +109,9 @@ func (library *Library) GetGraphTemplate(origin, source, template, filter string
 	// Load template from filesystem if needed
 	if !library.ItemExists(id, LibraryItemGraphTemplate) {
 		graph := &Graph{
-			Item:      Item{Name: template, Modified: library.Config.Origins[origin].Modified},
-			StackMode: library.Config.Origins[origin].Templates[template].StackMode,
+			Item:       Item{Name: template, Modified: library.Config.Origins[origin].Modified},
+			StackMode:  library.Config.Origins[origin].Templates[template].StackMode,
+			Embeddable: library.Config.Origins[origin].Templates[template].Embeddable,
 		}
 
 		for i, tmplStack := range library.Config.Origins[origin].Templates[template].Stacks {
@@ -145,6 +158,52 @@ func (library *Library) GetGraphTemplate(origin, source, template, filter string
 	return library.TemplateGraphs[id], nil
 }
 
+func (graph *Graph) series() []*Serie {
+	series := make([]*Serie, 0)
+
+	for _, stack := range graph.Stacks {
+		if stack == nil {
+			continue
+		}
+
+		for _, group := range stack.Groups {
+			if group == nil {
+				continue
+			}
+
+			for _, serie := range group.Series {
+				if serie != nil {
+					series = append(series, serie)
+				}
+			}
+		}
+	}
+
+	return series
+}
+
+func (serie *Serie) groupNames() map[int]string {
+	result := make(map[int]string)
+
+	if strings.HasPrefix(serie.Source, LibraryGroupPrefix) {
+		result[LibraryItemSourceGroup] = strings.TrimPrefix(serie.Source, LibraryGroupPrefix)
+	}
+
+	if strings.HasPrefix(serie.Metric, LibraryGroupPrefix) {
+		result[LibraryItemMetricGroup] = strings.TrimPrefix(serie.Metric, LibraryGroupPrefix)
+	}
+
+	return result
+}
+
+func (serie *Serie) setGroupName(groupType int, name string) {
+	if groupType == LibraryItemSourceGroup {
+		serie.Source = LibraryGroupPrefix + name
+	} else if groupType == LibraryItemMetricGroup {
+		serie.Metric = LibraryGroupPrefix + name
+	}
+}
+
 func (library *Library) getTemplateID(origin, name string) (string, error) {
 	id, err := uuid.NewV3(uuid.NamespaceURL, []byte(origin+name))
 	if err != nil {
@@ -153,3 +212,31 @@ func (library *Library) getTemplateID(origin, name string) (string, error) {
 
 	return id.String(), nil
 }
+
+func validateProcessing(fill string, transforms []*plot.Transform, forecast *plot.Forecast,
+	anomaly *plot.Anomaly) error {
+
+	if err := plot.ValidateFill(fill); err != nil {
+		return err
+	}
+
+	for _, transform := range transforms {
+		if transform == nil {
+			return fmt.Errorf("found null transformation")
+		} else if err := transform.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if forecast != nil {
+		if err := forecast.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if anomaly != nil {
+		return anomaly.Validate()
+	}
+
+	return nil
+}
@@ -36,7 +36,7 @@ func (library *Library) ExpandGroup(name string, groupType int) []string {
 
 	item, err := library.GetItemByName(name, groupType)
 	if err != nil {
-		log.Printf("ERROR: " + err.Error())
+		log.Println("ERROR: " + err.Error())
 		return make([]string, 0)
 	}
 
@@ -8,6 +8,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/facette/facette/pkg/connector"
+	"github.com/facette/facette/pkg/plot"
 	"github.com/facette/facette/pkg/utils"
 	"github.com/facette/facette/thirdparty/github.com/fatih/set"
 	"github.com/facette/facette/thirdparty/github.com/nu7hatch/gouuid"
@@ -19,6 +21,7 @@ type Item struct {
 	ID          string    `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
 	Modified    time.Time `json:"-"`
 }
 
@@ -53,12 +56,28 @@ func (library *Library) DeleteItem(id string, itemType int) error {
 		delete(library.Groups, id)
 
 	case LibraryItemGraph:
+		// Remove dangling collections entries
+		library.removeGraphReferences(id)
+
 		delete(library.Graphs, id)
 
 	case LibraryItemCollection:
 		delete(library.Collections, id)
+
+	case LibraryItemAlert:
+		library.alertsMutex.Lock()
+		delete(library.Alerts, id)
+		library.alertsMutex.Unlock()
+
+	case LibraryItemPlaylist:
+		delete(library.Playlists, id)
 	}
 
+	library.updateReferences()
+
+	// Reset dynamic collections generated entries
+	library.resetExpandedEntries()
+
 	return nil
 }
 
@@ -83,6 +102,19 @@ func (library *Library) GetItem(id string, itemType int) (interface{}, error) {
 
 	case LibraryItemCollection:
 		return library.Collections[id], nil
+
+	case LibraryItemAlert:
+		library.alertsMutex.RLock()
+		defer library.alertsMutex.RUnlock()
+
+		if alert, ok := library.Alerts[id]; ok {
+			return alert, nil
+		}
+
+		return nil, os.ErrNotExist
+
+	case LibraryItemPlaylist:
+		return library.Playlists[id], nil
 	}
 
 	return nil, fmt.Errorf("no item found")
@@ -115,6 +147,27 @@ func (library *Library) GetItemByName(name string, itemType int) (interface{}, e
 				continue
 			}
 
+			return item, nil
+		}
+
+	case LibraryItemAlert:
+		library.alertsMutex.RLock()
+		defer library.alertsMutex.RUnlock()
+
+		for _, item := range library.Alerts {
+			if item.Name != name {
+				continue
+			}
+
+			return item, nil
+		}
+
+	case LibraryItemPlaylist:
+		for _, item := range library.Playlists {
+			if item.Name != name {
+				continue
+			}
+
 			return item, nil
 		}
 	}
@@ -140,6 +193,14 @@ func (library *Library) ItemExists(id string, itemType int) bool {
 
 	case LibraryItemCollection:
 		_, exists = library.Collections[id]
+
+	case LibraryItemAlert:
+		library.alertsMutex.RLock()
+		_, exists = library.Alerts[id]
+		library.alertsMutex.RUnlock()
+
+	case LibraryItemPlaylist:
+		_, exists = library.Playlists[id]
 	}
 
 	return exists
@@ -201,6 +262,35 @@ func (library *Library) LoadItem(id string, itemType int) error {
 		}
 
 		library.Collections[id].Modified = fileInfo.ModTime()
+
+	case LibraryItemAlert:
+		tmpAlert := &Alert{}
+
+		filePath := library.getFilePath(id, itemType)
+
+		fileInfo, err := utils.JSONLoad(filePath, &tmpAlert)
+		if err != nil {
+			return fmt.Errorf("in %s, %s", filePath, err.Error())
+		}
+
+		tmpAlert.Modified = fileInfo.ModTime()
+
+		library.alertsMutex.Lock()
+		library.Alerts[id] = tmpAlert
+		library.alertsMutex.Unlock()
+
+	case LibraryItemPlaylist:
+		tmpPlaylist := &Playlist{}
+
+		filePath := library.getFilePath(id, itemType)
+
+		fileInfo, err := utils.JSONLoad(filePath, &tmpPlaylist)
+		if err != nil {
+			return fmt.Errorf("in %s, %s", filePath, err.Error())
+		}
+
+		library.Playlists[id] = tmpPlaylist
+		library.Playlists[id].Modified = fileInfo.ModTime()
 	}
 
 	return nil
@@ -208,18 +298,11 @@ func (library *Library) LoadItem(id string, itemType int) error {
 
 // StoreItem stores an item into the library.
 func (library *Library) StoreItem(item interface{}, itemType int) error {
-	var itemStruct *Item
-
-	switch itemType {
-	case LibraryItemSourceGroup, LibraryItemMetricGroup:
-		itemStruct = item.(*Group).GetItem()
-
-	case LibraryItemGraph:
-		itemStruct = item.(*Graph).GetItem()
+	return library.storeItem(item, itemType, false)
+}
 
-	case LibraryItemCollection:
-		itemStruct = item.(*Collection).GetItem()
-	}
+func (library *Library) storeItem(item interface{}, itemType int, keepID bool) error {
+	itemStruct := getItemStruct(item, itemType)
 
 	if itemStruct.ID == "" {
 		uuidTemp, err := uuid.NewV4()
@@ -228,10 +311,12 @@ func (library *Library) StoreItem(item interface{}, itemType int) error {
 		}
 
 		itemStruct.ID = uuidTemp.String()
-	} else if !library.ItemExists(itemStruct.ID, itemType) {
+	} else if !keepID && !library.ItemExists(itemStruct.ID, itemType) {
 		return os.ErrNotExist
 	}
 
+	itemStruct.Tags = normalizeTags(itemStruct.Tags)
+
 	// Check for name field presence/duplicates
 	if itemStruct.Name == "" && (itemType != LibraryItemGraph ||
 		itemType == LibraryItemGraph && !item.(*Graph).Volatile) {
@@ -258,12 +343,30 @@ func (library *Library) StoreItem(item interface{}, itemType int) error {
 				log.Printf("ERROR: duplicate `%s' collection identifier", itemStruct.ID)
 				return os.ErrExist
 			}
+
+		case LibraryItemAlert:
+			if itemTemp.(*Alert).ID != itemStruct.ID {
+				log.Printf("ERROR: duplicate `%s' alert identifier", itemStruct.ID)
+				return os.ErrExist
+			}
+
+		case LibraryItemPlaylist:
+			if itemTemp.(*Playlist).ID != itemStruct.ID {
+				log.Printf("ERROR: duplicate `%s' playlist identifier", itemStruct.ID)
+				return os.ErrExist
+			}
 		}
 	}
 
 	// Store item into library
 	switch itemType {
 	case LibraryItemSourceGroup, LibraryItemMetricGroup:
+		// Follow group renaming into referencing graphs
+		if groupTemp, ok := library.Groups[itemStruct.ID]; ok && groupTemp.Name != itemStruct.Name {
+			item.(*Group).ID = itemStruct.ID
+			library.renameGroupReferences(item.(*Group), groupTemp.Name)
+		}
+
 		library.Groups[itemStruct.ID] = item.(*Group)
 		library.Groups[itemStruct.ID].ID = itemStruct.ID
 
@@ -295,6 +398,23 @@ func (library *Library) StoreItem(item interface{}, itemType int) error {
 
 				groupSet.Add(group.Name)
 
+				if err := validateProcessing(group.Fill, group.Transforms, group.Forecast,
+					group.Anomaly); err != nil {
+					log.Printf("ERROR: in `%s' group, %s", group.Name, err)
+					return os.ErrInvalid
+				}
+
+				if group.Type == connector.OperGroupTypeExpression {
+					if len(group.Series) > 0 {
+						log.Printf("ERROR: expression group `%s' can't have series", group.Name)
+						return os.ErrInvalid
+					} else if _, err := plot.ParseExpression(group.Expression); err != nil &&
+						!templateVariableRegexp.MatchString(group.Expression) {
+						log.Printf("ERROR: in `%s' group expression, %s", group.Name, err)
+						return os.ErrInvalid
+					}
+				}
+
 				for _, serie := range group.Series {
 					if serie == nil {
 						log.Printf("ERROR: found null serie in `%s' group", group.Name)
@@ -305,6 +425,12 @@ func (library *Library) StoreItem(item interface{}, itemType int) error {
 					}
 
 					serieSet.Add(serie.Name)
+
+					if err := validateProcessing(serie.Fill, serie.Transforms, serie.Forecast,
+						serie.Anomaly); err != nil {
+						log.Printf("ERROR: in `%s' serie, %s", serie.Name, err)
+						return os.ErrInvalid
+					}
 				}
 			}
 		}
@@ -313,8 +439,34 @@ func (library *Library) StoreItem(item interface{}, itemType int) error {
 		library.Graphs[itemStruct.ID].ID = itemStruct.ID
 
 	case LibraryItemCollection:
+		if item.(*Collection).Type == CollectionTypeDynamic && item.(*Collection).Group == "" {
+			log.Println("ERROR: dynamic collection has no source group")
+			return os.ErrInvalid
+		}
+
 		library.Collections[itemStruct.ID] = item.(*Collection)
 		library.Collections[itemStruct.ID].ID = itemStruct.ID
+
+	case LibraryItemAlert:
+		if err := library.validateAlert(item.(*Alert)); err != nil {
+			log.Printf("ERROR: in `%s' alert, %s", itemStruct.Name, err)
+			return os.ErrInvalid
+		}
+
+		item.(*Alert).ID = itemStruct.ID
+
+		library.alertsMutex.Lock()
+		library.Alerts[itemStruct.ID] = item.(*Alert)
+		library.alertsMutex.Unlock()
+
+	case LibraryItemPlaylist:
+		if err := library.validatePlaylist(item.(*Playlist)); err != nil {
+			log.Printf("ERROR: in `%s' playlist, %s", itemStruct.Name, err)
+			return os.ErrInvalid
+		}
+
+		library.Playlists[itemStruct.ID] = item.(*Playlist)
+		library.Playlists[itemStruct.ID].ID = itemStruct.ID
 	}
 
 	// Store JSON data
@@ -324,29 +476,63 @@ func (library *Library) StoreItem(item interface{}, itemType int) error {
 		}
 	}
 
+	library.updateReferences()
+
+	// Reset dynamic collections generated entries
+	library.resetExpandedEntries()
+
 	return nil
 }
 
 func (library *Library) getDirPath(itemType int) string {
-	var dirName string
+	return path.Join(library.Config.DataDir, getItemDirName(itemType))
+}
+
+func (library *Library) getFilePath(id string, itemType int) string {
+	return path.Join(library.getDirPath(itemType), id[0:2], id[2:4], id+".json")
+}
 
+func getItemStruct(item interface{}, itemType int) *Item {
+	switch itemType {
+	case LibraryItemSourceGroup, LibraryItemMetricGroup:
+		return item.(*Group).GetItem()
+
+	case LibraryItemGraph:
+		return item.(*Graph).GetItem()
+
+	case LibraryItemCollection:
+		return item.(*Collection).GetItem()
+
+	case LibraryItemAlert:
+		return item.(*Alert).GetItem()
+
+	case LibraryItemPlaylist:
+		return item.(*Playlist).GetItem()
+	}
+
+	return nil
+}
+
+func getItemDirName(itemType int) string {
 	switch itemType {
 	case LibraryItemSourceGroup:
-		dirName = "sourcegroups"
+		return "sourcegroups"
 
 	case LibraryItemMetricGroup:
-		dirName = "metricgroups"
+		return "metricgroups"
 
 	case LibraryItemGraph:
-		dirName = "graphs"
+		return "graphs"
 
 	case LibraryItemCollection:
-		dirName = "collections"
-	}
+		return "collections"
 
-	return path.Join(library.Config.DataDir, dirName)
-}
+	case LibraryItemAlert:
+		return "alerts"
 
-func (library *Library) getFilePath(id string, itemType int) string {
-	return path.Join(library.getDirPath(itemType), id[0:2], id[2:4], id+".json")
+	case LibraryItemPlaylist:
+		return "playlists"
+	}
+
+	return ""
 }
@@ -8,6 +8,8 @@ import (
 	"path"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/facette/facette/pkg/catalog"
 	"github.com/facette/facette/pkg/config"
@@ -25,23 +27,34 @@ const (
 	LibraryItemGraphTemplate
 	// LibraryItemCollection represents a collection item.
 	LibraryItemCollection
+	// LibraryItemAlert represents an alert rule item.
+	LibraryItemAlert
+	// LibraryItemPlaylist represents a collections playlist item.
+	LibraryItemPlaylist
 )
 
 const (
 	// UUIDPattern represents an UUID validation pattern.
-	UUIDPattern = "^\\d{8}-(?:\\d{4}-){3}\\d{12}$"
+	UUIDPattern = "^[0-9a-f]{8}-(?:[0-9a-f]{4}-){3}[0-9a-f]{12}$"
 )
 
 // Library represents the main structure of library instance.
 type Library struct {
-	Config         *config.Config
-	Catalog        *catalog.Catalog
-	Groups         map[string]*Group
-	Graphs         map[string]*Graph
-	TemplateGraphs map[string]*Graph
-	Collections    map[string]*Collection
-	debugLevel     int
-	idRegexp       *regexp.Regexp
+	Config          *config.Config
+	Catalog         *catalog.Catalog
+	Groups          map[string]*Group
+	Graphs          map[string]*Graph
+	TemplateGraphs  map[string]*Graph
+	Collections     map[string]*Collection
+	Alerts          map[string]*Alert
+	Playlists       map[string]*Playlist
+	debugLevel      int
+	idRegexp        *regexp.Regexp
+	references      map[referenceKey]map[referenceKey]bool
+	expandedEntries map[string][]*CollectionEntry
+	expandTime      time.Time
+	expandMutex     sync.Mutex
+	alertsMutex     sync.RWMutex
 }
 
 // Refresh updates the current library by browsing the filesystem for stored data.
@@ -53,6 +66,11 @@ func (library *Library) Refresh() error {
 	library.Graphs = make(map[string]*Graph)
 	library.TemplateGraphs = make(map[string]*Graph)
 	library.Collections = make(map[string]*Collection)
+	library.Playlists = make(map[string]*Playlist)
+
+	library.alertsMutex.Lock()
+	library.Alerts = make(map[string]*Alert)
+	library.alertsMutex.Unlock()
 
 	walkFunc := func(filePath string, fileInfo os.FileInfo, fileError error) error {
 		mode := fileInfo.Mode() & os.ModeType
@@ -76,6 +94,8 @@ func (library *Library) Refresh() error {
 		LibraryItemMetricGroup,
 		LibraryItemGraph,
 		LibraryItemCollection,
+		LibraryItemAlert,
+		LibraryItemPlaylist,
 	} {
 		dirPath := library.getDirPath(itemType)
 
@@ -89,23 +109,38 @@ func (library *Library) Refresh() error {
 	}
 
 	// Update collection items parent-children relations
+	library.linkCollections()
+
+	// Update items references index
+	library.updateReferences()
+
+	// Reset dynamic collections generated entries
+	library.resetExpandedEntries()
+
+	log.Println("INFO: library refresh completed")
+
+	return nil
+}
+
+func (library *Library) linkCollections() {
+	for _, collection := range library.Collections {
+		collection.Parent = nil
+		collection.Children = nil
+	}
+
 	for _, collection := range library.Collections {
 		if collection.ParentID == "" {
 			continue
 		}
 
 		if _, ok := library.Collections[collection.ParentID]; !ok {
-			log.Println("ERROR: unknown `%s' parent identifier", collection.ParentID)
+			log.Printf("ERROR: unknown `%s' parent identifier", collection.ParentID)
 			continue
 		}
 
 		collection.Parent = library.Collections[collection.ParentID]
 		collection.Parent.Children = append(collection.Parent.Children, collection)
 	}
-
-	log.Println("INFO: library refresh completed")
-
-	return nil
 }
 
 // NewLibrary creates a new instance of library.
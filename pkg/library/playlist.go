@@ -0,0 +1,85 @@
+package library
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/facette/facette/pkg/utils"
+)
+
+const (
+	// DefaultPlaylistInterval represents the default time each playlist page is displayed for.
+	DefaultPlaylistInterval = "30s"
+	// DefaultPlaylistRefresh represents the default interval between playlist graphs data refreshes.
+	DefaultPlaylistRefresh = "1m"
+)
+
+// Playlist represents a collections playlist item, cycled through by the kiosk view.
+type Playlist struct {
+	Item
+	Collections []string `json:"collections"`
+	Interval    string   `json:"interval"`
+	Refresh     string   `json:"refresh"`
+	PageSize    int      `json:"page_size"`
+	Range       string   `json:"range"`
+	Theme       string   `json:"theme"`
+}
+
+// GetDurations returns the time each playlist page is displayed for and the interval between graphs data refreshes.
+func (playlist *Playlist) GetDurations() (time.Duration, time.Duration, error) {
+	result := make([]time.Duration, 2)
+
+	for i, entry := range [][2]string{
+		{playlist.Interval, DefaultPlaylistInterval},
+		{playlist.Refresh, DefaultPlaylistRefresh},
+	} {
+		if entry[0] == "" {
+			entry[0] = entry[1]
+		}
+
+		refTime := time.Now()
+
+		value, err := utils.TimeApplyRange(refTime, entry[0])
+		if err != nil || !value.After(refTime) {
+			return 0, 0, fmt.Errorf("invalid `%s' range", entry[0])
+		}
+
+		result[i] = value.Sub(refTime)
+	}
+
+	return result[0], result[1], nil
+}
+
+func (library *Library) validatePlaylist(playlist *Playlist) error {
+	if len(playlist.Collections) == 0 {
+		return fmt.Errorf("missing collections")
+	}
+
+	for _, collectionID := range playlist.Collections {
+		if !library.ItemExists(collectionID, LibraryItemCollection) {
+			return fmt.Errorf("unknown `%s' collection", collectionID)
+		}
+	}
+
+	if _, _, err := playlist.GetDurations(); err != nil {
+		return err
+	} else if playlist.PageSize < 0 {
+		return fmt.Errorf("page size must be positive")
+	}
+
+	if playlist.Range != "" {
+		refTime := time.Now()
+
+		if value, err := utils.TimeApplyRange(refTime, playlist.Range); err != nil || !value.Before(refTime) {
+			return fmt.Errorf("invalid `%s' range", playlist.Range)
+		}
+	}
+
+	switch playlist.Theme {
+	case "", "light", "dark":
+	default:
+		return fmt.Errorf("unknown `%s' theme", playlist.Theme)
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package library
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/facette/facette/pkg/utils"
+)
+
+// ItemReference represents a reference to a library item.
+type ItemReference struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ItemReferenceList represents a list of library items references.
+type ItemReferenceList []*ItemReference
+
+func (r ItemReferenceList) Len() int {
+	return len(r)
+}
+
+func (r ItemReferenceList) Less(i, j int) bool {
+	if r[i].Type != r[j].Type {
+		return r[i].Type < r[j].Type
+	}
+
+	return r[i].Name < r[j].Name
+}
+
+func (r ItemReferenceList) Swap(i, j int) {
+	r[i], r[j] = r[j], r[i]
+}
+
+type referenceKey struct {
+	itemType int
+	id       string
+}
+
+// GetItemUsage returns the list of items referencing a given library item.
+func (library *Library) GetItemUsage(id string, itemType int) ItemReferenceList {
+	result := make(ItemReferenceList, 0)
+
+	for key := range library.references[referenceKey{itemType, id}] {
+		itemStruct := library.getItemStructByID(key.id, key.itemType)
+		if itemStruct == nil {
+			continue
+		}
+
+		result = append(result, &ItemReference{
+			Type: getItemDirName(key.itemType),
+			ID:   itemStruct.ID,
+			Name: itemStruct.Name,
+		})
+	}
+
+	sort.Sort(result)
+
+	return result
+}
+
+// IsItemUsed returns whether an item is referenced by other items or not.
+func (library *Library) IsItemUsed(id string, itemType int) bool {
+	return len(library.references[referenceKey{itemType, id}]) > 0
+}
+
+func (library *Library) getItemStructByID(id string, itemType int) *Item {
+	if !library.ItemExists(id, itemType) {
+		return nil
+	}
+
+	switch itemType {
+	case LibraryItemSourceGroup, LibraryItemMetricGroup:
+		return library.Groups[id].GetItem()
+
+	case LibraryItemGraph:
+		return library.Graphs[id].GetItem()
+
+	case LibraryItemCollection:
+		return library.Collections[id].GetItem()
+
+	case LibraryItemAlert:
+		library.alertsMutex.RLock()
+		defer library.alertsMutex.RUnlock()
+
+		return library.Alerts[id].GetItem()
+
+	case LibraryItemPlaylist:
+		return library.Playlists[id].GetItem()
+	}
+
+	return nil
+}
+
+func (library *Library) addReference(target, source referenceKey) {
+	if _, ok := library.references[target]; !ok {
+		library.references[target] = make(map[referenceKey]bool)
+	}
+
+	library.references[target][source] = true
+}
+
+func (library *Library) updateReferences() {
+	library.references = make(map[referenceKey]map[referenceKey]bool)
+
+	// Index groups by their names
+	groupIDs := make(map[int]map[string]string)
+	groupIDs[LibraryItemSourceGroup] = make(map[string]string)
+	groupIDs[LibraryItemMetricGroup] = make(map[string]string)
+
+	for _, group := range library.Groups {
+		groupIDs[group.Type][group.Name] = group.ID
+	}
+
+	// Register groups references from graphs series
+	for _, graph := range library.Graphs {
+		if graph.Volatile {
+			continue
+		}
+
+		for _, serie := range graph.series() {
+			for groupType, groupName := range serie.groupNames() {
+				if groupID, ok := groupIDs[groupType][groupName]; ok {
+					library.addReference(referenceKey{groupType, groupID},
+						referenceKey{LibraryItemGraph, graph.ID})
+				}
+			}
+		}
+	}
+
+	// Register graphs references from collections entries and parents references from children
+	for _, collection := range library.Collections {
+		if _, ok := library.Collections[collection.ParentID]; ok {
+			library.addReference(referenceKey{LibraryItemCollection, collection.ParentID},
+				referenceKey{LibraryItemCollection, collection.ID})
+		}
+
+		for _, entry := range collection.Entries {
+			if _, ok := library.Graphs[entry.ID]; ok {
+				library.addReference(referenceKey{LibraryItemGraph, entry.ID},
+					referenceKey{LibraryItemCollection, collection.ID})
+			}
+		}
+
+		if collection.Type != CollectionTypeDynamic {
+			continue
+		}
+
+		// Register source group and graph templates references from dynamic collections
+		if groupID, ok := groupIDs[LibraryItemSourceGroup][collection.Group]; ok {
+			library.addReference(referenceKey{LibraryItemSourceGroup, groupID},
+				referenceKey{LibraryItemCollection, collection.ID})
+		}
+
+		for _, templateID := range collection.Templates {
+			if _, ok := library.Graphs[templateID]; ok {
+				library.addReference(referenceKey{LibraryItemGraph, templateID},
+					referenceKey{LibraryItemCollection, collection.ID})
+			}
+		}
+	}
+
+	// Register graphs references from alert rules
+	for _, alert := range library.GetAlerts() {
+		if _, ok := library.Graphs[alert.Graph]; ok {
+			library.addReference(referenceKey{LibraryItemGraph, alert.Graph},
+				referenceKey{LibraryItemAlert, alert.ID})
+		}
+	}
+
+	// Register collections references from playlists
+	for _, playlist := range library.Playlists {
+		for _, collectionID := range playlist.Collections {
+			if _, ok := library.Collections[collectionID]; ok {
+				library.addReference(referenceKey{LibraryItemCollection, collectionID},
+					referenceKey{LibraryItemPlaylist, playlist.ID})
+			}
+		}
+	}
+}
+
+func (library *Library) renameGroupReferences(group *Group, oldName string) {
+	for key := range library.references[referenceKey{group.Type, group.ID}] {
+		if collection, ok := library.Collections[key.id]; ok && key.itemType == LibraryItemCollection {
+			collection.Group = group.Name
+			collection.Modified = time.Now()
+
+			if err := utils.JSONDump(library.getFilePath(collection.ID, LibraryItemCollection), collection,
+				collection.Modified); err != nil {
+				log.Println("ERROR: " + err.Error())
+			}
+
+			continue
+		}
+
+		graph, ok := library.Graphs[key.id]
+		if !ok {
+			continue
+		}
+
+		for _, serie := range graph.series() {
+			if name, ok := serie.groupNames()[group.Type]; ok && name == oldName {
+				serie.setGroupName(group.Type, group.Name)
+			}
+		}
+
+		graph.Modified = time.Now()
+
+		if err := utils.JSONDump(library.getFilePath(graph.ID, LibraryItemGraph), graph, graph.Modified); err != nil {
+			log.Println("ERROR: " + err.Error())
+		}
+	}
+}
+
+func (library *Library) removeGraphReferences(id string) {
+	for key := range library.references[referenceKey{LibraryItemGraph, id}] {
+		collection, ok := library.Collections[key.id]
+		if !ok {
+			continue
+		}
+
+		entries := make([]*CollectionEntry, 0)
+
+		for _, entry := range collection.Entries {
+			if entry.ID != id {
+				entries = append(entries, entry)
+			}
+		}
+
+		templates := make([]string, 0)
+
+		for _, templateID := range collection.Templates {
+			if templateID != id {
+				templates = append(templates, templateID)
+			}
+		}
+
+		collection.Entries = entries
+		collection.Templates = templates
+		collection.Modified = time.Now()
+
+		if err := utils.JSONDump(library.getFilePath(collection.ID, LibraryItemCollection), collection,
+			collection.Modified); err != nil {
+			log.Println("ERROR: " + err.Error())
+		}
+	}
+}
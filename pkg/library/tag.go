@@ -0,0 +1,102 @@
+package library
+
+import (
+	"sort"
+	"strings"
+)
+
+// HasTags returns whether an item has all the given tags or not.
+func (item *Item) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+
+		for _, itemTag := range item.Tags {
+			if itemTag == tag {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetTags returns the tags used by items of the given types along with their occurrences count.
+func (library *Library) GetTags(itemTypes ...int) map[string]int {
+	result := make(map[string]int)
+
+	if len(itemTypes) == 0 {
+		itemTypes = []int{LibraryItemSourceGroup, LibraryItemMetricGroup, LibraryItemGraph, LibraryItemCollection,
+			LibraryItemAlert, LibraryItemPlaylist}
+	}
+
+	for _, itemType := range itemTypes {
+		items := make([]*Item, 0)
+
+		switch itemType {
+		case LibraryItemSourceGroup, LibraryItemMetricGroup:
+			for _, group := range library.Groups {
+				if group.Type == itemType {
+					items = append(items, group.GetItem())
+				}
+			}
+
+		case LibraryItemGraph:
+			for _, graph := range library.Graphs {
+				if !graph.Volatile {
+					items = append(items, graph.GetItem())
+				}
+			}
+
+		case LibraryItemCollection:
+			for _, collection := range library.Collections {
+				items = append(items, collection.GetItem())
+			}
+
+		case LibraryItemAlert:
+			for _, alert := range library.GetAlerts() {
+				items = append(items, alert.GetItem())
+			}
+
+		case LibraryItemPlaylist:
+			for _, playlist := range library.Playlists {
+				items = append(items, playlist.GetItem())
+			}
+		}
+
+		for _, item := range items {
+			for _, tag := range item.Tags {
+				result[tag]++
+			}
+		}
+	}
+
+	return result
+}
+
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	result := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		result = append(result, tag)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
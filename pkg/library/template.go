@@ -0,0 +1,82 @@
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/facette/facette/thirdparty/github.com/fatih/set"
+)
+
+var templateVariableRegexp = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_\-\.]+)\s*\}\}`)
+
+// GetTemplateVariables returns the sorted list of variables names used in a graph template.
+func (graph *Graph) GetTemplateVariables() []string {
+	variableSet := set.New()
+
+	for _, value := range graph.templateFields() {
+		for _, match := range templateVariableRegexp.FindAllStringSubmatch(*value, -1) {
+			variableSet.Add(match[1])
+		}
+	}
+
+	result := set.StringSlice(variableSet)
+	sort.Strings(result)
+
+	return result
+}
+
+// InstantiateGraph generates a new graph from a graph template and a set of variables values.
+func (library *Library) InstantiateGraph(template *Graph, attributes map[string]string) (*Graph, error) {
+	if !template.Template {
+		return nil, fmt.Errorf("`%s' graph is not a template", template.Name)
+	}
+
+	for _, name := range template.GetTemplateVariables() {
+		if _, ok := attributes[name]; !ok {
+			return nil, fmt.Errorf("missing value for `%s' template variable", name)
+		}
+	}
+
+	// Deep copy the template through JSON as groups options hold arbitrary decoded values
+	data, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &Graph{}
+	if err = json.Unmarshal(data, graph); err != nil {
+		return nil, err
+	}
+
+	for _, value := range graph.templateFields() {
+		*value = templateVariableRegexp.ReplaceAllStringFunc(*value, func(match string) string {
+			return attributes[templateVariableRegexp.FindStringSubmatch(match)[1]]
+		})
+	}
+
+	graph.ID = template.ID
+	graph.Template = false
+	graph.Modified = template.Modified
+
+	return graph, nil
+}
+
+func (graph *Graph) templateFields() []*string {
+	fields := []*string{&graph.Name, &graph.Description}
+
+	for _, stack := range graph.Stacks {
+		for _, group := range stack.Groups {
+			if group != nil && group.Expression != "" {
+				fields = append(fields, &group.Expression)
+			}
+		}
+	}
+
+	for _, serie := range graph.series() {
+		fields = append(fields, &serie.Name, &serie.Origin, &serie.Source, &serie.Metric)
+	}
+
+	return fields
+}
@@ -0,0 +1,58 @@
+package library
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_InstantiateGraph(test *testing.T) {
+	options := map[string]interface{}{
+		"colors":  []interface{}{"#f00", "#0f0"},
+		"display": map[string]interface{}{"legend": true, "ticks": 5.0},
+	}
+
+	template := &Graph{
+		Item:     Item{ID: "graph1", Name: "{{ host }} load"},
+		Template: true,
+		Stacks: []*Stack{&Stack{
+			Name: "stack0",
+			Groups: []*OperGroup{&OperGroup{
+				Name:    "group0",
+				Series:  []*Serie{&Serie{Name: "load", Origin: "origin1", Source: "{{ host }}", Metric: "load"}},
+				Options: options,
+			}},
+		}},
+	}
+
+	graph, err := (&Library{}).InstantiateGraph(template, map[string]string{"host": "host1"})
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	if graph.Name != "host1 load" || graph.Template {
+		test.Logf("\nExpected `%s' instance\nbut got  `%s' (template: %v)", "host1 load", graph.Name, graph.Template)
+		test.Fail()
+	}
+
+	if len(graph.Stacks) != 1 || len(graph.Stacks[0].Groups) != 1 || len(graph.Stacks[0].Groups[0].Series) != 1 {
+		test.Logf("\nExpected 1 stack with 1 group and 1 serie\nbut got  %+v", graph.Stacks)
+		test.FailNow()
+	}
+
+	if source := graph.Stacks[0].Groups[0].Series[0].Source; source != "host1" {
+		test.Logf("\nExpected source `%s'\nbut got  `%s'", "host1", source)
+		test.Fail()
+	}
+
+	if !reflect.DeepEqual(graph.Stacks[0].Groups[0].Options, options) {
+		test.Logf("\nExpected options %v\nbut got  %v", options, graph.Stacks[0].Groups[0].Options)
+		test.Fail()
+	}
+
+	// Check template is left untouched
+	if template.Name != "{{ host }} load" || template.Stacks[0].Groups[0].Series[0].Source != "{{ host }}" {
+		test.Logf("\nExpected template to be left untouched\nbut got  %+v", template)
+		test.Fail()
+	}
+}
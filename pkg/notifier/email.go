@@ -0,0 +1,163 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/facette/facette/pkg/config"
+)
+
+const (
+	emailDefaultSubject = "[Facette] {{.Name}} is {{if .Flapping}}flapping{{else}}{{.State}}{{end}}"
+	emailDefaultBody    = `Alert:    {{.Name}}
+State:    {{.State}} (previously {{.Previous}}){{if .Flapping}}, flapping{{end}}{{if .Repeat}}, repeated{{end}}
+{{if .Serie}}Serie:    {{.Serie}}
+Value:    {{.Value}}
+{{end}}{{if .Message}}Message:  {{.Message}}
+{{end}}Time:     {{.Time.Format "2006-01-02 15:04:05 MST"}}
+`
+)
+
+// EmailChannel represents the main structure of the email notification channel.
+type EmailChannel struct {
+	Host     string
+	Username string
+	Password string
+	From     string
+	To       []string
+	Subject  *template.Template
+	Body     *template.Template
+	Timeout  time.Duration
+}
+
+// Send sends the notification by email to the channel recipients using the SMTP server.
+func (channel *EmailChannel) Send(notification *Notification) error {
+	subject, err := executeTemplate(channel.Subject, notification)
+	if err != nil {
+		return err
+	}
+
+	body, err := executeTemplate(channel.Body, notification)
+	if err != nil {
+		return err
+	}
+
+	message := bytes.NewBuffer(nil)
+
+	fmt.Fprintf(message, "From: %s\r\n", channel.From)
+	fmt.Fprintf(message, "To: %s\r\n", strings.Join(channel.To, ", "))
+	fmt.Fprintf(message, "Subject: %s\r\n", encodeHeader(string(subject)))
+	fmt.Fprintf(message, "Date: %s\r\n", notification.Time.Format(time.RFC1123Z))
+	fmt.Fprintf(message, "Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+
+	message.Write(bytes.Replace(body, []byte("\n"), []byte("\r\n"), -1))
+
+	return channel.sendMail(message.Bytes())
+}
+
+func encodeHeader(value string) string {
+	// Prevent headers injection from line breaks (e.g. coming from rule names) and encode non-ASCII characters
+	value = strings.TrimSpace(strings.NewReplacer("\r", " ", "\n", " ").Replace(value))
+
+	return mime.QEncoding.Encode("utf-8", value)
+}
+
+func (channel *EmailChannel) sendMail(message []byte) error {
+	conn, err := net.DialTimeout("tcp", channel.Host, channel.Timeout)
+	if err != nil {
+		return err
+	}
+
+	conn.SetDeadline(time.Now().Add(channel.Timeout))
+
+	host, _, _ := net.SplitHostPort(channel.Host)
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+
+	if channel.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", channel.Username, channel.Password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err = client.Mail(channel.From); err != nil {
+		return err
+	}
+
+	for _, recipient := range channel.To {
+		if err = client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err = writer.Write(message); err != nil {
+		return err
+	} else if err = writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func init() {
+	Channels["email"] = func(config *config.ChannelConfig) (Channel, error) {
+		if config.Host == "" {
+			return nil, fmt.Errorf("missing `host' mandatory channel setting")
+		} else if config.From == "" {
+			return nil, fmt.Errorf("missing `from' mandatory channel setting")
+		} else if len(config.To) == 0 {
+			return nil, fmt.Errorf("missing `to' mandatory channel setting")
+		}
+
+		channel := &EmailChannel{
+			Host:     config.Host,
+			Username: config.Username,
+			Password: config.Password,
+			From:     config.From,
+			To:       config.To,
+			Timeout:  DefaultTimeout * time.Second,
+		}
+
+		if _, _, err := net.SplitHostPort(channel.Host); err != nil {
+			channel.Host = net.JoinHostPort(channel.Host, "25")
+		}
+
+		if config.Timeout > 0 {
+			channel.Timeout = time.Duration(config.Timeout) * time.Second
+		}
+
+		var err error
+
+		if channel.Subject, err = parseTemplate("subject", config.Subject, emailDefaultSubject); err != nil {
+			return nil, err
+		} else if channel.Body, err = parseTemplate("body", config.Template, emailDefaultBody); err != nil {
+			return nil, err
+		}
+
+		return channel, nil
+	}
+}
@@ -0,0 +1,170 @@
+package notifier
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/config"
+)
+
+type smtpSink struct {
+	listener   net.Listener
+	recipients []string
+	data       string
+	done       chan bool
+}
+
+func newSMTPSink(test *testing.T) *smtpSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	sink := &smtpSink{listener: listener, done: make(chan bool)}
+
+	go sink.serve()
+
+	return sink
+}
+
+func (sink *smtpSink) serve() {
+	defer close(sink.done)
+
+	conn, err := sink.listener.Accept()
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP sink")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch command {
+		case "EHLO", "HELO":
+			text.PrintfLine("250 localhost")
+
+		case "RCPT":
+			sink.recipients = append(sink.recipients, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			text.PrintfLine("250 OK")
+
+		case "DATA":
+			text.PrintfLine("354 Go ahead")
+
+			lines, _ := text.ReadDotLines()
+			sink.data = strings.Join(lines, "\n")
+
+			text.PrintfLine("250 OK")
+
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+
+		default:
+			text.PrintfLine("250 OK")
+		}
+	}
+}
+
+func Test_EmailSend(test *testing.T) {
+	sink := newSMTPSink(test)
+	defer sink.listener.Close()
+
+	channel, err := Channels["email"](&config.ChannelConfig{
+		Host: sink.listener.Addr().String(),
+		From: "facette@example.net",
+		To:   []string{"ops@example.net", "oncall@example.net"},
+	})
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	notification := &Notification{
+		Alert:    "rule1",
+		Name:     "rule1",
+		State:    "warning",
+		Previous: "ok",
+		Serie:    "serie1",
+		Value:    84.5,
+		Time:     time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if err = channel.Send(notification); err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	<-sink.done
+
+	if expected := []string{"ops@example.net", "oncall@example.net"}; strings.Join(sink.recipients, ",") !=
+		strings.Join(expected, ",") {
+		test.Logf("\nExpected %v\nbut got  %v", expected, sink.recipients)
+		test.Fail()
+	}
+
+	for _, expected := range []string{
+		"Subject: [Facette] rule1 is warning",
+		"State:    warning (previously ok)",
+		"Value:    84.5",
+	} {
+		if !strings.Contains(sink.data, expected) {
+			test.Logf("\nExpected message to contain %q\nbut got  %s", expected, sink.data)
+			test.Fail()
+		}
+	}
+
+	// Check subject line breaks and non-ASCII characters are safely encoded
+	for _, entry := range [][2]string{
+		{"rule1\r\nBcc: evil@example.net", "Subject: [Facette] rule1  Bcc: evil@example.net is warning\n"},
+		{"règle1", "Subject: =?utf-8?q?[Facette]_r=C3=A8gle1_is_warning?=\n"},
+	} {
+		sink = newSMTPSink(test)
+		defer sink.listener.Close()
+
+		channel, _ = Channels["email"](&config.ChannelConfig{
+			Host: sink.listener.Addr().String(),
+			From: "facette@example.net",
+			To:   []string{"ops@example.net"},
+		})
+
+		notification.Name = entry[0]
+
+		if err = channel.Send(notification); err != nil {
+			test.Logf("\nExpected no error\nbut got  %s", err)
+			test.FailNow()
+		}
+
+		<-sink.done
+
+		if header := strings.SplitN(sink.data, "\n\n", 2)[0] + "\n"; !strings.Contains(header, entry[1]) ||
+			strings.Contains(header, "\nBcc:") {
+			test.Logf("\nExpected message header to contain %q\nbut got  %s", entry[1], header)
+			test.Fail()
+		}
+	}
+
+	for _, channelConfig := range []*config.ChannelConfig{
+		{From: "facette@example.net", To: []string{"ops@example.net"}},
+		{Host: "localhost", To: []string{"ops@example.net"}},
+		{Host: "localhost", From: "facette@example.net"},
+		{Host: "localhost", From: "facette@example.net", To: []string{"ops@example.net"}, Subject: "{{"},
+	} {
+		if _, err = Channels["email"](channelConfig); err == nil {
+			test.Logf("\nExpected %+v to be invalid", channelConfig)
+			test.Fail()
+		}
+	}
+}
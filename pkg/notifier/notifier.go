@@ -0,0 +1,308 @@
+// Package notifier implements the notification of alert rules state changes through external channels.
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/facette/facette/pkg/alert"
+	"github.com/facette/facette/pkg/config"
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/types"
+	"github.com/facette/facette/pkg/utils"
+)
+
+const (
+	// DefaultFlapWindow represents the default time window alert rules state changes are counted over to detect
+	// flapping.
+	DefaultFlapWindow = "1h"
+	// DefaultFlapChanges represents the default number of state changes within the flapping window from which
+	// notifications are suppressed.
+	DefaultFlapChanges = 5
+	// DefaultHistorySize represents the default maximum number of notifications kept in history.
+	DefaultHistorySize = 1000
+	// DefaultTimeout represents the default channels sending timeout (in seconds).
+	DefaultTimeout = 10
+)
+
+var (
+	// Channels represents the list of all available notification channel handlers.
+	Channels = make(map[string]func(*config.ChannelConfig) (Channel, error))
+)
+
+// Channel represents the main interface of a notification channel handler.
+type Channel interface {
+	Send(notification *Notification) error
+}
+
+// Notification represents an alert rule state change notification.
+type Notification struct {
+	Alert    string          `json:"alert"`
+	Name     string          `json:"name"`
+	State    string          `json:"state"`
+	Previous string          `json:"previous"`
+	Serie    string          `json:"serie,omitempty"`
+	Value    types.PlotValue `json:"value"`
+	Message  string          `json:"message,omitempty"`
+	Time     time.Time       `json:"time"`
+	Repeat   bool            `json:"repeat,omitempty"`
+	Flapping bool            `json:"flapping,omitempty"`
+}
+
+// Record represents a notification history entry.
+type Record struct {
+	Notification
+	Channel string
+	Error   string
+}
+
+// Notifier represents the main structure of a notifier instance.
+type Notifier struct {
+	Config      *config.Config
+	channels    map[string]Channel
+	rules       map[string]*ruleState
+	history     []*Record
+	flapWindow  time.Duration
+	flapChanges int
+	historySize int
+	mutex       sync.Mutex
+	debugLevel  int
+}
+
+type ruleState struct {
+	notified string
+	sent     time.Time
+	changes  []time.Time
+	flapping bool
+}
+
+// Refresh updates the notifier channels and settings from the configuration.
+func (notifier *Notifier) Refresh() error {
+	var errOutput error
+
+	notifyConfig := notifier.Config.Notify
+	if notifyConfig == nil {
+		notifyConfig = &config.NotifyConfig{}
+	}
+
+	channels := make(map[string]Channel)
+
+	for name, channelConfig := range notifyConfig.Channels {
+		if _, ok := Channels[channelConfig.Type]; !ok {
+			errOutput = fmt.Errorf("unknown `%s' notification channel type", channelConfig.Type)
+			log.Println("ERROR: " + errOutput.Error())
+			continue
+		}
+
+		channel, err := Channels[channelConfig.Type](channelConfig)
+		if err != nil {
+			errOutput = fmt.Errorf("in `%s' notification channel, %s", name, err.Error())
+			log.Println("ERROR: " + errOutput.Error())
+			continue
+		}
+
+		channels[name] = channel
+	}
+
+	flapWindow := notifyConfig.FlapWindow
+	if flapWindow == "" {
+		flapWindow = DefaultFlapWindow
+	}
+
+	refTime := time.Now()
+
+	flapTime, err := utils.TimeApplyRange(refTime, flapWindow)
+	if err != nil || flapTime.Before(refTime) {
+		errOutput = fmt.Errorf("invalid `%s' flapping window", flapWindow)
+		log.Println("ERROR: " + errOutput.Error())
+
+		flapTime, _ = utils.TimeApplyRange(refTime, DefaultFlapWindow)
+	}
+
+	notifier.mutex.Lock()
+	defer notifier.mutex.Unlock()
+
+	notifier.channels = channels
+	notifier.flapWindow = flapTime.Sub(refTime)
+
+	if notifier.flapChanges = notifyConfig.FlapChanges; notifier.flapChanges <= 0 {
+		notifier.flapChanges = DefaultFlapChanges
+	}
+
+	if notifier.historySize = notifyConfig.HistorySize; notifier.historySize <= 0 {
+		notifier.historySize = DefaultHistorySize
+	}
+
+	return errOutput
+}
+
+// Handle processes the state of an alert rule following its evaluation, sending notifications to the rule channels on
+// state changes and then at the rule repeat interval while not in ok state. Notifications are suppressed while the
+// rule is flapping, until no state change occurs during the flapping window.
+func (notifier *Notifier) Handle(rule *library.Alert, state *alert.State, previous string) {
+	if len(rule.Notify) == 0 {
+		return
+	}
+
+	repeat, _ := rule.GetRepeat()
+	now := state.Checked
+
+	notifier.mutex.Lock()
+
+	current, ok := notifier.rules[rule.ID]
+	if !ok {
+		current = &ruleState{}
+		notifier.rules[rule.ID] = current
+	}
+
+	// Only keep state changes within the flapping detection window
+	changes := make([]time.Time, 0)
+	for _, change := range current.changes {
+		if now.Sub(change) < notifier.flapWindow {
+			changes = append(changes, change)
+		}
+	}
+
+	if state.State != previous {
+		changes = append(changes, now)
+	}
+
+	current.changes = changes
+
+	notification := &Notification{
+		Alert:    rule.ID,
+		Name:     rule.Name,
+		State:    state.State,
+		Previous: previous,
+		Serie:    state.Serie,
+		Value:    state.Value,
+		Message:  state.Message,
+		Time:     now,
+	}
+
+	send := false
+
+	if len(current.changes) >= notifier.flapChanges {
+		// Only notify once when the rule starts flapping
+		if !current.flapping {
+			current.flapping = true
+			notification.Flapping = true
+			send = true
+		}
+	} else if !current.flapping || len(current.changes) == 0 {
+		// Flapping rules notifications are suppressed until no state change occurs during the flapping window
+		current.flapping = false
+
+		if current.notified == "" && (state.State == alert.StateOK || state.State == alert.StateUnknown) {
+			// Initial ok and unknown states (e.g. while the catalog is loading) are not notified, waiting for an
+			// actual state change
+		} else if state.State != current.notified {
+			send = true
+		} else if repeat > 0 && state.State != alert.StateOK && now.Sub(current.sent) >= repeat {
+			notification.Repeat = true
+			send = true
+		}
+	}
+
+	if send {
+		current.notified = state.State
+		current.sent = now
+	}
+
+	channels := make(map[string]Channel)
+	for _, name := range rule.Notify {
+		channels[name] = notifier.channels[name]
+	}
+
+	notifier.mutex.Unlock()
+
+	if !send {
+		return
+	}
+
+	for _, name := range rule.Notify {
+		notifier.send(name, channels[name], notification)
+	}
+}
+
+// History returns the notifications history, most recent ones first. If `alertID' is not empty, only the
+// notifications of this alert rule are returned.
+func (notifier *Notifier) History(alertID string) []*Record {
+	notifier.mutex.Lock()
+	defer notifier.mutex.Unlock()
+
+	result := make([]*Record, 0)
+
+	for i := len(notifier.history) - 1; i >= 0; i-- {
+		if alertID == "" || notifier.history[i].Alert == alertID {
+			record := *notifier.history[i]
+			result = append(result, &record)
+		}
+	}
+
+	return result
+}
+
+func (notifier *Notifier) send(name string, channel Channel, notification *Notification) {
+	var err error
+
+	if channel == nil {
+		err = fmt.Errorf("unknown `%s' notification channel", name)
+	} else {
+		err = channel.Send(notification)
+	}
+
+	record := &Record{Notification: *notification, Channel: name}
+
+	if err != nil {
+		record.Error = err.Error()
+		log.Printf("ERROR: unable to notify alert `%s' through `%s' channel: %s", notification.Name, name, err)
+	} else if notifier.debugLevel > 0 {
+		log.Printf("DEBUG: notified alert `%s' %s state through `%s' channel", notification.Name,
+			notification.State, name)
+	}
+
+	notifier.mutex.Lock()
+	defer notifier.mutex.Unlock()
+
+	notifier.history = append(notifier.history, record)
+
+	if len(notifier.history) > notifier.historySize {
+		notifier.history = notifier.history[len(notifier.history)-notifier.historySize:]
+	}
+}
+
+func parseTemplate(name, text, defaultText string) (*template.Template, error) {
+	if text == "" {
+		text = defaultText
+	}
+
+	return template.New(name).Parse(text)
+}
+
+func executeTemplate(tmpl *template.Template, notification *Notification) ([]byte, error) {
+	buffer := bytes.NewBuffer(nil)
+
+	if err := tmpl.Execute(buffer, notification); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// NewNotifier creates a new instance of notifier.
+func NewNotifier(config *config.Config, debugLevel int) *Notifier {
+	return &Notifier{
+		Config:      config,
+		channels:    make(map[string]Channel),
+		rules:       make(map[string]*ruleState),
+		history:     make([]*Record, 0),
+		flapChanges: DefaultFlapChanges,
+		historySize: DefaultHistorySize,
+		debugLevel:  debugLevel,
+	}
+}
@@ -0,0 +1,179 @@
+package notifier
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/alert"
+	"github.com/facette/facette/pkg/config"
+	"github.com/facette/facette/pkg/library"
+)
+
+type testChannel struct {
+	notifications []*Notification
+	fail          bool
+}
+
+func (channel *testChannel) Send(notification *Notification) error {
+	if channel.fail {
+		return fmt.Errorf("sending failed")
+	}
+
+	channel.notifications = append(channel.notifications, notification)
+
+	return nil
+}
+
+func newTestNotifier(test *testing.T, flapChanges int) (*Notifier, *testChannel) {
+	channel := &testChannel{}
+
+	Channels["test"] = func(config *config.ChannelConfig) (Channel, error) {
+		return channel, nil
+	}
+
+	notifier := NewNotifier(&config.Config{Notify: &config.NotifyConfig{
+		Channels:    map[string]*config.ChannelConfig{"test": {Type: "test"}},
+		FlapWindow:  "1h",
+		FlapChanges: flapChanges,
+		HistorySize: 3,
+	}}, 0)
+
+	if err := notifier.Refresh(); err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	return notifier, channel
+}
+
+func Test_NotifierHandle(test *testing.T) {
+	notifier, channel := newTestNotifier(test, 5)
+
+	rule := &library.Alert{Notify: []string{"test"}, Repeat: "30m"}
+	rule.ID, rule.Name = "rule1", "rule1"
+
+	refTime := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	previous := alert.StateUnknown
+
+	for _, entry := range []struct {
+		offset   time.Duration
+		state    string
+		expected string
+		repeat   bool
+	}{
+		// Initial unknown and ok states are neither notified nor repeated
+		{-40 * time.Minute, alert.StateUnknown, "", false},
+		{-5 * time.Minute, alert.StateUnknown, "", false},
+		{0, alert.StateOK, "", false},
+		{time.Minute, alert.StateCritical, alert.StateCritical, false},
+		{2 * time.Minute, alert.StateCritical, "", false},
+		// Repeat while not in ok state
+		{31 * time.Minute, alert.StateCritical, alert.StateCritical, true},
+		{32 * time.Minute, alert.StateWarning, alert.StateWarning, false},
+		{33 * time.Minute, alert.StateOK, alert.StateOK, false},
+		{70 * time.Minute, alert.StateOK, "", false},
+	} {
+		channel.notifications = nil
+
+		notifier.Handle(rule, &alert.State{State: entry.state, Checked: refTime.Add(entry.offset)}, previous)
+		previous = entry.state
+
+		if entry.expected == "" {
+			if len(channel.notifications) != 0 {
+				test.Logf("\nExpected no notification at %s\nbut got  %s", entry.offset,
+					channel.notifications[0].State)
+				test.Fail()
+			}
+
+			continue
+		}
+
+		if len(channel.notifications) != 1 {
+			test.Logf("\nExpected 1 notification at %s\nbut got  %d", entry.offset, len(channel.notifications))
+			test.Fail()
+		} else if notification := channel.notifications[0]; notification.State != entry.expected ||
+			notification.Repeat != entry.repeat {
+			test.Logf("\nExpected %s (repeat: %v) at %s\nbut got  %s (repeat: %v)", entry.expected, entry.repeat,
+				entry.offset, notification.State, notification.Repeat)
+			test.Fail()
+		}
+	}
+
+	// Check history is bounded and sorted from most recent notifications
+	history := notifier.History("rule1")
+
+	if len(history) != 3 || history[0].State != alert.StateOK || history[2].State != alert.StateCritical {
+		test.Logf("\nExpected 3 records from ok to critical\nbut got  %+v", history)
+		test.Fail()
+	}
+
+	if history := notifier.History("rule2"); len(history) != 0 {
+		test.Logf("\nExpected no record\nbut got  %d", len(history))
+		test.Fail()
+	}
+}
+
+func Test_NotifierFlapping(test *testing.T) {
+	notifier, channel := newTestNotifier(test, 3)
+
+	rule := &library.Alert{Notify: []string{"test"}}
+	rule.ID, rule.Name = "rule1", "rule1"
+
+	refTime := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)
+	states := []string{alert.StateOK, alert.StateCritical, alert.StateOK, alert.StateCritical, alert.StateOK,
+		alert.StateCritical}
+
+	previous := alert.StateOK
+
+	for i, state := range states {
+		notifier.Handle(rule, &alert.State{State: state, Checked: refTime.Add(time.Duration(i) * time.Minute)},
+			previous)
+		previous = state
+	}
+
+	// Expect critical and ok notifications, then a single flapping one
+	if len(channel.notifications) != 3 || !channel.notifications[2].Flapping {
+		test.Logf("\nExpected 3 notifications, the last one flapping\nbut got  %d", len(channel.notifications))
+		test.FailNow()
+	}
+
+	// Check notifications resume once state changes settle down
+	notifier.Handle(rule, &alert.State{State: alert.StateCritical, Checked: refTime.Add(30 * time.Minute)},
+		alert.StateCritical)
+
+	notifier.Handle(rule, &alert.State{State: alert.StateOK, Checked: refTime.Add(2 * time.Hour)},
+		alert.StateCritical)
+
+	notifier.Handle(rule, &alert.State{State: alert.StateOK, Checked: refTime.Add(4 * time.Hour)}, alert.StateOK)
+
+	if len(channel.notifications) != 4 || channel.notifications[3].State != alert.StateOK {
+		test.Logf("\nExpected 4 notifications, the last one ok\nbut got  %d", len(channel.notifications))
+		test.Fail()
+	}
+}
+
+func Test_NotifierErrors(test *testing.T) {
+	notifier, channel := newTestNotifier(test, 5)
+	channel.fail = true
+
+	rule := &library.Alert{Notify: []string{"test", "unknown"}}
+	rule.ID, rule.Name = "rule1", "rule1"
+
+	notifier.Handle(rule, &alert.State{State: alert.StateCritical, Checked: time.Now()}, alert.StateOK)
+
+	history := notifier.History("")
+
+	if len(history) != 2 || history[0].Error == "" || history[1].Error == "" {
+		test.Logf("\nExpected 2 records with errors\nbut got  %+v", history)
+		test.Fail()
+	}
+
+	// Check unknown channel types are reported
+	notifier.Config.Notify.Channels["test"].Type = "unknown"
+
+	if err := notifier.Refresh(); err == nil {
+		test.Logf("\nExpected error\nbut got  nil")
+		test.Fail()
+	}
+}
@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/facette/facette/pkg/config"
+)
+
+// WebhookChannel represents the main structure of the webhook notification channel.
+type WebhookChannel struct {
+	URL      string
+	Headers  map[string]string
+	Template *template.Template
+	Timeout  time.Duration
+}
+
+// Send posts the notification to the webhook URL, either as a JSON object or rendered using the channel template.
+func (channel *WebhookChannel) Send(notification *Notification) error {
+	var (
+		body []byte
+		err  error
+	)
+
+	if channel.Template != nil {
+		body, err = executeTemplate(channel.Template, notification)
+	} else {
+		body, err = json.Marshal(notification)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("POST", channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	for key, value := range channel.Headers {
+		request.Header.Set(key, value)
+	}
+
+	httpClient := http.Client{Timeout: channel.Timeout}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	// Drain response body for the connection to be reused
+	ioutil.ReadAll(response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("got HTTP status code %d, expected 2xx", response.StatusCode)
+	}
+
+	return nil
+}
+
+func init() {
+	Channels["webhook"] = func(config *config.ChannelConfig) (Channel, error) {
+		if config.URL == "" {
+			return nil, fmt.Errorf("missing `url' mandatory channel setting")
+		}
+
+		channel := &WebhookChannel{
+			URL:     config.URL,
+			Headers: config.Headers,
+			Timeout: DefaultTimeout * time.Second,
+		}
+
+		if config.Timeout > 0 {
+			channel.Timeout = time.Duration(config.Timeout) * time.Second
+		}
+
+		if config.Template != "" {
+			tmpl, err := parseTemplate("webhook", config.Template, "")
+			if err != nil {
+				return nil, err
+			}
+
+			channel.Template = tmpl
+		}
+
+		return channel, nil
+	}
+}
@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/config"
+)
+
+func Test_WebhookSend(test *testing.T) {
+	var (
+		body    []byte
+		headers http.Header
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ = ioutil.ReadAll(request.Body)
+		headers = request.Header
+
+		if request.URL.Path == "/fail" {
+			writer.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	notification := &Notification{
+		Alert:    "rule1",
+		Name:     "rule1",
+		State:    "critical",
+		Previous: "ok",
+		Serie:    "serie1",
+		Value:    42,
+		Time:     time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Check default JSON payload and custom headers
+	channel, err := Channels["webhook"](&config.ChannelConfig{
+		URL:     server.URL,
+		Headers: map[string]string{"X-Token": "secret"},
+	})
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	if err = channel.Send(notification); err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	result := &Notification{}
+
+	if err = json.Unmarshal(body, result); err != nil || *result != *notification {
+		test.Logf("\nExpected %+v\nbut got  %s", notification, body)
+		test.Fail()
+	}
+
+	if headers.Get("X-Token") != "secret" || headers.Get("Content-Type") != "application/json" {
+		test.Logf("\nExpected custom and JSON content type headers\nbut got  %v", headers)
+		test.Fail()
+	}
+
+	// Check templated payload
+	channel, _ = Channels["webhook"](&config.ChannelConfig{
+		URL:      server.URL,
+		Template: `{"text": "{{.Name}} is {{.State}} ({{.Value}})"}`,
+	})
+
+	if err = channel.Send(notification); err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	if expected := `{"text": "rule1 is critical (42)"}`; string(body) != expected {
+		test.Logf("\nExpected %s\nbut got  %s", expected, body)
+		test.Fail()
+	}
+
+	// Check HTTP errors are reported
+	channel, _ = Channels["webhook"](&config.ChannelConfig{URL: server.URL + "/fail"})
+
+	if err = channel.Send(notification); err == nil {
+		test.Logf("\nExpected error\nbut got  nil")
+		test.Fail()
+	}
+
+	if _, err = Channels["webhook"](&config.ChannelConfig{}); err == nil {
+		test.Logf("\nExpected missing URL error\nbut got  nil")
+		test.Fail()
+	}
+}
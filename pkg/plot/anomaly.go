@@ -0,0 +1,238 @@
+package plot
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/facette/facette/pkg/types"
+	"github.com/facette/facette/pkg/utils"
+)
+
+const (
+	// AnomalyMAD represents an anomaly analysis based on a rolling median absolute deviation.
+	AnomalyMAD = "mad"
+	// AnomalySeasonal represents an anomaly analysis based on the comparison with previous seasonal periods.
+	AnomalySeasonal = "seasonal"
+
+	// DefaultAnomalyWindow represents the default number of points of the rolling analysis window.
+	DefaultAnomalyWindow = 30
+	// DefaultAnomalyPeriods represents the default number of previous periods of the seasonal analysis.
+	DefaultAnomalyPeriods = 3
+	// DefaultAnomalyDeviations represents the default number of deviations from the baseline for a value to be
+	// considered anomalous.
+	DefaultAnomalyDeviations = 3
+
+	// Scale factors making the median and mean absolute deviations consistent estimators of the standard deviation
+	madScale    = 1.4826
+	meanADScale = 1.2533
+)
+
+// Anomaly represents a plots values anomaly analysis definition.
+type Anomaly struct {
+	Method     string  `json:"method"`
+	Window     int     `json:"window"`
+	Season     string  `json:"season"`
+	Periods    int     `json:"periods"`
+	Deviations float64 `json:"deviations"`
+}
+
+// Detection represents the result of a plots values anomaly analysis. Anomalous intervals are expressed as ranges of
+// plots values indexes, their end being excluded.
+type Detection struct {
+	Upper     []types.PlotValue
+	Lower     []types.PlotValue
+	Intervals [][2]int
+}
+
+// Validate checks for the anomaly analysis definition validity.
+func (anomaly *Anomaly) Validate() error {
+	switch anomaly.Method {
+	case "", AnomalyMAD:
+
+	case AnomalySeasonal:
+		if season, err := anomaly.seasonDuration(time.Now()); err != nil || season <= 0 {
+			return fmt.Errorf("invalid `%s' anomaly analysis season", anomaly.Season)
+		}
+
+	default:
+		return fmt.Errorf("unknown `%s' anomaly analysis method", anomaly.Method)
+	}
+
+	if anomaly.Window < 0 {
+		return fmt.Errorf("invalid `%d' anomaly analysis window", anomaly.Window)
+	} else if anomaly.Periods < 0 {
+		return fmt.Errorf("invalid `%d' anomaly analysis periods", anomaly.Periods)
+	} else if anomaly.Deviations < 0 {
+		return fmt.Errorf("invalid `%g' anomaly analysis deviations", anomaly.Deviations)
+	}
+
+	return nil
+}
+
+func (anomaly *Anomaly) seasonDuration(refTime time.Time) (time.Duration, error) {
+	seasonTime, err := utils.TimeApplyRange(refTime, anomaly.Season)
+	if err != nil {
+		return 0, err
+	}
+
+	return seasonTime.Sub(refTime), nil
+}
+
+// PeriodStarts returns the start times of the previous periods a seasonal analysis compares the plots values starting
+// at a given time with.
+func (anomaly *Anomaly) PeriodStarts(startTime time.Time) ([]time.Time, error) {
+	if anomaly.Method != AnomalySeasonal {
+		return nil, nil
+	}
+
+	season, err := anomaly.seasonDuration(startTime)
+	if err != nil {
+		return nil, err
+	}
+
+	periods := anomaly.Periods
+	if periods == 0 {
+		periods = DefaultAnomalyPeriods
+	}
+
+	result := make([]time.Time, periods)
+
+	for i := range result {
+		result[i] = startTime.Add(-time.Duration(i+1) * season)
+	}
+
+	return result, nil
+}
+
+// Detect computes the normal envelope of a set of plots values and the intervals of values lying outside of it.
+// Seasonal analyses rely on the plots values of the previous periods, following the order of PeriodStarts.
+func (anomaly *Anomaly) Detect(plots []types.PlotValue, periods [][]types.PlotValue) *Detection {
+	deviations := anomaly.Deviations
+	if deviations == 0 {
+		deviations = DefaultAnomalyDeviations
+	}
+
+	var baseline, scale []float64
+
+	if anomaly.Method == AnomalySeasonal {
+		baseline, scale = seasonalBaseline(len(plots), periods)
+	} else {
+		window := anomaly.Window
+		if window == 0 {
+			window = DefaultAnomalyWindow
+		}
+
+		baseline, scale = rollingBaseline(plots, window)
+	}
+
+	detection := &Detection{
+		Upper:     make([]types.PlotValue, len(plots)),
+		Lower:     make([]types.PlotValue, len(plots)),
+		Intervals: make([][2]int, 0),
+	}
+
+	start := -1
+
+	for i := 0; i <= len(plots); i++ {
+		anomalous := false
+
+		if i < len(plots) {
+			delta := deviations * madScale * scale[i]
+
+			detection.Upper[i] = types.PlotValue(baseline[i] + delta)
+			detection.Lower[i] = types.PlotValue(baseline[i] - delta)
+
+			anomalous = !math.IsNaN(float64(plots[i])) && !math.IsNaN(baseline[i]) &&
+				(plots[i] > detection.Upper[i] || plots[i] < detection.Lower[i])
+		}
+
+		// Merge consecutive anomalous values into intervals
+		if anomalous && start == -1 {
+			start = i
+		} else if !anomalous && start != -1 {
+			detection.Intervals = append(detection.Intervals, [2]int{start, i})
+			start = -1
+		}
+	}
+
+	return detection
+}
+
+func rollingBaseline(plots []types.PlotValue, window int) ([]float64, []float64) {
+	baseline := make([]float64, len(plots))
+	scale := make([]float64, len(plots))
+
+	for i := range plots {
+		values := make([]float64, 0)
+
+		// Only consider previous values for the current one not to weigh on its own baseline
+		for j := i - window; j < i; j++ {
+			if j >= 0 && !math.IsNaN(float64(plots[j])) {
+				values = append(values, float64(plots[j]))
+			}
+		}
+
+		if len(values) < 3 {
+			baseline[i], scale[i] = math.NaN(), math.NaN()
+			continue
+		}
+
+		baseline[i] = median(values)
+		scale[i] = medianDeviation(values, baseline[i])
+	}
+
+	return baseline, scale
+}
+
+func seasonalBaseline(length int, periods [][]types.PlotValue) ([]float64, []float64) {
+	baseline := make([]float64, length)
+	scale := make([]float64, length)
+	deviations := make([]float64, 0)
+
+	for i := range baseline {
+		values := make([]float64, 0)
+
+		for _, plots := range periods {
+			if i < len(plots) && !math.IsNaN(float64(plots[i])) {
+				values = append(values, float64(plots[i]))
+			}
+		}
+
+		baseline[i] = median(values)
+
+		for _, value := range values {
+			deviations = append(deviations, math.Abs(value-baseline[i]))
+		}
+	}
+
+	// Use the deviation of previous periods from their baseline pooled over the whole range, as only a few values are
+	// available for each point
+	pooled := robustDeviation(deviations)
+
+	for i := range scale {
+		scale[i] = pooled
+	}
+
+	return baseline, scale
+}
+
+func medianDeviation(values []float64, center float64) float64 {
+	deviations := make([]float64, len(values))
+
+	for i, value := range values {
+		deviations[i] = math.Abs(value - center)
+	}
+
+	return robustDeviation(deviations)
+}
+
+func robustDeviation(deviations []float64) float64 {
+	// Fall back on the mean absolute deviation if most of the values are equal to the median, as any variation would
+	// be considered as anomalous otherwise
+	if result := median(deviations); result != 0 {
+		return result
+	}
+
+	return average(deviations) * meanADScale / madScale
+}
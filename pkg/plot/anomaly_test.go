@@ -0,0 +1,84 @@
+package plot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+func Test_AnomalyValidate(test *testing.T) {
+	for _, anomaly := range []*Anomaly{
+		{},
+		{Method: AnomalyMAD, Window: 10, Deviations: 2},
+		{Method: AnomalySeasonal, Season: "1d", Periods: 4},
+	} {
+		if err := anomaly.Validate(); err != nil {
+			test.Logf("\nExpected %+v to be valid\nbut got  %s", anomaly, err)
+			test.Fail()
+		}
+	}
+
+	for _, anomaly := range []*Anomaly{
+		{Method: "unknown"},
+		{Method: AnomalySeasonal},
+		{Method: AnomalySeasonal, Season: "-1d"},
+		{Window: -1},
+		{Deviations: -1},
+	} {
+		if err := anomaly.Validate(); err == nil {
+			test.Logf("\nExpected %+v to be invalid", anomaly)
+			test.Fail()
+		}
+	}
+}
+
+func Test_AnomalyDetectMAD(test *testing.T) {
+	plots := []types.PlotValue{10, 11, 10, 9, 10, 11, 10, 30, 31, 10, nan, 9, 10, 0}
+
+	detection := (&Anomaly{Window: 5}).Detect(plots, nil)
+
+	if expected := [][2]int{{7, 9}, {13, 14}}; !reflect.DeepEqual(expected, detection.Intervals) {
+		test.Logf("\nExpected %v\nbut got  %v", expected, detection.Intervals)
+		test.Fail()
+	}
+
+	// Check bands are missing until enough values are available
+	testPlotsEqual(test, []types.PlotValue{nan, nan, nan}, detection.Upper[:3])
+
+	// Check the mean absolute deviation fallback if most values are equal to the median
+	delta := types.PlotValue(3 * 0.4 * meanADScale)
+
+	testPlotsEqual(test, []types.PlotValue{10 + delta, 10 - delta}, []types.PlotValue{detection.Upper[5],
+		detection.Lower[5]})
+}
+
+func Test_AnomalyDetectSeasonal(test *testing.T) {
+	anomaly := &Anomaly{Method: AnomalySeasonal, Season: "1d", Periods: 2, Deviations: 2}
+
+	startTime := time.Date(2014, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	periodStarts, err := anomaly.PeriodStarts(startTime)
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	expectedStarts := []time.Time{startTime.AddDate(0, 0, -1), startTime.AddDate(0, 0, -2)}
+
+	if !reflect.DeepEqual(expectedStarts, periodStarts) {
+		test.Logf("\nExpected %v\nbut got  %v", expectedStarts, periodStarts)
+		test.Fail()
+	}
+
+	detection := anomaly.Detect([]types.PlotValue{1, 5, 3, 9, 1}, [][]types.PlotValue{
+		{1, 5, 4, 1, 2},
+		{2, 6, 3, 2},
+	})
+
+	if expected := [][2]int{{3, 4}}; !reflect.DeepEqual(expected, detection.Intervals) {
+		test.Logf("\nExpected %v\nbut got  %v", expected, detection.Intervals)
+		test.Fail()
+	}
+}
@@ -0,0 +1,183 @@
+package plot
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+const (
+	// DownsampleAverage represents a downsampling averaging values in buckets.
+	DownsampleAverage = "average"
+	// DownsampleMinMax represents a downsampling keeping minimum and maximum values of buckets.
+	DownsampleMinMax = "minmax"
+	// DownsampleLTTB represents a Largest-Triangle-Three-Buckets downsampling.
+	DownsampleLTTB = "lttb"
+)
+
+// ValidateDownsample checks for the downsampling mode validity.
+func ValidateDownsample(mode string) error {
+	switch mode {
+	case "", DownsampleAverage, DownsampleMinMax, DownsampleLTTB:
+		return nil
+	}
+
+	return fmt.Errorf("unknown `%s' downsampling mode", mode)
+}
+
+// Downsample reduces a set of plots values to at most `sample' points using a given downsampling mode, defaulting to
+// the average one.
+func Downsample(plots []types.PlotValue, sample int, mode string) []types.PlotValue {
+	if sample <= 0 || len(plots) <= sample {
+		return plots
+	}
+
+	switch mode {
+	case DownsampleMinMax:
+		if sample >= 2 {
+			return downsampleMinMax(plots, sample/2)
+		}
+
+	case DownsampleLTTB:
+		if sample >= 3 {
+			return downsampleLTTB(plots, sample)
+		}
+	}
+
+	return downsampleAverage(plots, sample)
+}
+
+func bucketBounds(length, buckets, index int) (int, int) {
+	return index * length / buckets, (index + 1) * length / buckets
+}
+
+func downsampleAverage(plots []types.PlotValue, buckets int) []types.PlotValue {
+	result := make([]types.PlotValue, buckets)
+
+	for i := range result {
+		start, end := bucketBounds(len(plots), buckets, i)
+		values := make([]float64, 0)
+
+		for _, plot := range plots[start:end] {
+			if !math.IsNaN(float64(plot)) {
+				values = append(values, float64(plot))
+			}
+		}
+
+		result[i] = types.PlotValue(average(values))
+	}
+
+	return result
+}
+
+func downsampleMinMax(plots []types.PlotValue, buckets int) []types.PlotValue {
+	result := make([]types.PlotValue, 0, buckets*2)
+
+	for i := 0; i < buckets; i++ {
+		start, end := bucketBounds(len(plots), buckets, i)
+		minIndex, maxIndex := -1, -1
+
+		for j := start; j < end; j++ {
+			if math.IsNaN(float64(plots[j])) {
+				continue
+			}
+
+			if minIndex == -1 || plots[j] < plots[minIndex] {
+				minIndex = j
+			}
+
+			if maxIndex == -1 || plots[j] > plots[maxIndex] {
+				maxIndex = j
+			}
+		}
+
+		// Keep bucket extrema in their chronological order, each bucket matching two returned fixed-step points
+		if minIndex == -1 {
+			result = append(result, types.PlotValue(math.NaN()), types.PlotValue(math.NaN()))
+		} else if minIndex <= maxIndex {
+			result = append(result, plots[minIndex], plots[maxIndex])
+		} else {
+			result = append(result, plots[maxIndex], plots[minIndex])
+		}
+	}
+
+	return result
+}
+
+func downsampleLTTB(plots []types.PlotValue, sample int) []types.PlotValue {
+	result := make([]types.PlotValue, 0, sample)
+
+	// Always keep first and last points, selecting one point per bucket in between. Buckets match the returned
+	// fixed-step points so that each selected value is returned within its original bucket time range.
+	result = append(result, plots[0])
+
+	anchor := 0
+
+	for i := 1; i < sample-1; i++ {
+		start, end := bucketBounds(len(plots), sample, i)
+
+		// Compute next bucket average point (last point for the last bucket)
+		var nextX, nextY float64
+
+		if i+1 < sample-1 {
+			nextStart, nextEnd := bucketBounds(len(plots), sample, i+1)
+			count := 0
+
+			for j := nextStart; j < nextEnd; j++ {
+				if !math.IsNaN(float64(plots[j])) {
+					nextX += float64(j)
+					nextY += float64(plots[j])
+					count++
+				}
+			}
+
+			if count > 0 {
+				nextX, nextY = nextX/float64(count), nextY/float64(count)
+			} else {
+				nextX, nextY = float64(nextEnd), math.NaN()
+			}
+		} else {
+			nextX, nextY = float64(len(plots)-1), float64(plots[len(plots)-1])
+		}
+
+		// Select the point forming the largest triangle with the anchor and next bucket average points
+		selected, maxArea := -1, -1.0
+
+		for j := start; j < end; j++ {
+			if math.IsNaN(float64(plots[j])) {
+				continue
+			}
+
+			area := triangleArea(float64(anchor), float64(plots[anchor]), float64(j), float64(plots[j]), nextX,
+				nextY)
+
+			if selected == -1 || area > maxArea {
+				selected, maxArea = j, area
+			}
+		}
+
+		if selected == -1 {
+			result = append(result, types.PlotValue(math.NaN()))
+			continue
+		}
+
+		result = append(result, plots[selected])
+		anchor = selected
+	}
+
+	return append(result, plots[len(plots)-1])
+}
+
+func triangleArea(ax, ay, bx, by, cx, cy float64) float64 {
+	// Ignore missing values by falling back on the distance between known points
+	if math.IsNaN(ay) {
+		ay = by
+	}
+
+	if math.IsNaN(cy) {
+		cy = by
+	}
+
+	return math.Abs((ax-cx)*(by-ay)-(ax-bx)*(cy-ay)) / 2
+}
@@ -0,0 +1,61 @@
+package plot
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+func Test_Downsample(test *testing.T) {
+	plots := make([]types.PlotValue, 1000)
+
+	for i := range plots {
+		plots[i] = types.PlotValue(math.Sin(float64(i) / 50))
+	}
+
+	plots[500] = 10
+	plots[700] = nan
+
+	for _, mode := range []string{"", DownsampleAverage, DownsampleMinMax, DownsampleLTTB} {
+		result := Downsample(plots, 101, mode)
+
+		if len(result) > 101 || len(result) < 100 {
+			test.Logf("\nExpected %d points\nbut got  %d (mode: %s)", 101, len(result), mode)
+			test.Fail()
+		}
+
+		// Check peaks are kept by the envelope-preserving modes
+		if mode == DownsampleMinMax || mode == DownsampleLTTB {
+			if info := Summarize(result, time.Time{}, 0, nil); info["max"] != 10 {
+				test.Logf("\nExpected max=%g\nbut got  max=%g (mode: %s)", 10.0, info["max"], mode)
+				test.Fail()
+			}
+		}
+	}
+
+	// Check short series are left untouched
+	testPlotsEqual(test, plots[:10], Downsample(plots[:10], 101, DownsampleLTTB))
+}
+
+func Test_DownsampleAverage(test *testing.T) {
+	testPlotsEqual(test, []types.PlotValue{1.5, 3, nan}, Downsample([]types.PlotValue{1, 2, 3, nan, nan, nan}, 3,
+		DownsampleAverage))
+}
+
+func Test_DownsampleMinMax(test *testing.T) {
+	testPlotsEqual(test, []types.PlotValue{5, 1, 2, 8}, Downsample([]types.PlotValue{3, 5, 1, 2, 8, 4}, 4,
+		DownsampleMinMax))
+}
+
+func Test_DownsampleLTTB(test *testing.T) {
+	testPlotsEqual(test, []types.PlotValue{0, 9, 0}, Downsample([]types.PlotValue{0, 1, 9, 2, 0}, 3,
+		DownsampleLTTB))
+
+	// Check selected values are returned at their fixed-step position
+	plots := make([]types.PlotValue, 20)
+	plots[7] = 9
+
+	testPlotsEqual(test, []types.PlotValue{0, 9, 0, 0, 0}, Downsample(plots, 5, DownsampleLTTB))
+}
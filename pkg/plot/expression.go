@@ -0,0 +1,327 @@
+package plot
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+// Expression represents a parsed arithmetic expression between series.
+type Expression struct {
+	root      expressionNode
+	variables []string
+}
+
+type expressionNode interface {
+	eval(series map[string][]types.PlotValue, index int) float64
+}
+
+type constantNode float64
+
+type variableNode string
+
+type unaryNode struct {
+	operand expressionNode
+}
+
+type binaryNode struct {
+	operator    rune
+	left, right expressionNode
+}
+
+type functionNode struct {
+	name string
+	args []expressionNode
+}
+
+type expressionParser struct {
+	input     []rune
+	position  int
+	variables map[string]bool
+}
+
+// ParseExpression parses an arithmetic expression. Expressions support `+', `-', `*' and `/' operators, parentheses,
+// numeric constants, `min()' and `max()' functions and series references either as bare names (e.g. `used') or
+// quoted ones (e.g. `"rx bytes"'). Expressions must reference at least one serie.
+func ParseExpression(input string) (*Expression, error) {
+	parser := &expressionParser{input: []rune(input), variables: make(map[string]bool)}
+
+	root, err := parser.parseSum()
+	if err != nil {
+		return nil, err
+	}
+
+	if parser.skipSpaces(); parser.position < len(parser.input) {
+		return nil, parser.errorf("unexpected `%c' character", parser.input[parser.position])
+	} else if len(parser.variables) == 0 {
+		// Results length is given by the referenced series
+		return nil, fmt.Errorf("expression doesn't reference any serie")
+	}
+
+	expression := &Expression{root: root, variables: make([]string, 0)}
+
+	for name := range parser.variables {
+		expression.variables = append(expression.variables, name)
+	}
+
+	sort.Strings(expression.variables)
+
+	return expression, nil
+}
+
+// Variables returns the sorted list of series names referenced by the expression.
+func (expression *Expression) Variables() []string {
+	return expression.variables
+}
+
+// Evaluate computes the expression point-by-point over a set of series plots values. Missing values propagate to the
+// result, as well as divisions by zero.
+func (expression *Expression) Evaluate(series map[string][]types.PlotValue) ([]types.PlotValue, error) {
+	length := 0
+
+	for _, name := range expression.variables {
+		plots, ok := series[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown `%s' serie", name)
+		} else if len(plots) > length {
+			length = len(plots)
+		}
+	}
+
+	result := make([]types.PlotValue, length)
+
+	for i := range result {
+		result[i] = types.PlotValue(expression.root.eval(series, i))
+	}
+
+	return result, nil
+}
+
+func (node constantNode) eval(series map[string][]types.PlotValue, index int) float64 {
+	return float64(node)
+}
+
+func (node variableNode) eval(series map[string][]types.PlotValue, index int) float64 {
+	// Pad series shorter than others with missing values
+	if plots := series[string(node)]; index < len(plots) {
+		return float64(plots[index])
+	}
+
+	return math.NaN()
+}
+
+func (node *unaryNode) eval(series map[string][]types.PlotValue, index int) float64 {
+	return -node.operand.eval(series, index)
+}
+
+func (node *binaryNode) eval(series map[string][]types.PlotValue, index int) float64 {
+	left, right := node.left.eval(series, index), node.right.eval(series, index)
+
+	switch node.operator {
+	case '+':
+		return left + right
+	case '-':
+		return left - right
+	case '*':
+		return left * right
+	case '/':
+		if right == 0 {
+			return math.NaN()
+		}
+
+		return left / right
+	}
+
+	return math.NaN()
+}
+
+func (node *functionNode) eval(series map[string][]types.PlotValue, index int) float64 {
+	result := node.args[0].eval(series, index)
+
+	for _, arg := range node.args[1:] {
+		value := arg.eval(series, index)
+
+		if math.IsNaN(value) || math.IsNaN(result) {
+			return math.NaN()
+		} else if node.name == "min" {
+			result = math.Min(result, value)
+		} else {
+			result = math.Max(result, value)
+		}
+	}
+
+	return result
+}
+
+func (parser *expressionParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at position %d: %s", parser.position+1, fmt.Sprintf(format, args...))
+}
+
+func (parser *expressionParser) skipSpaces() {
+	for parser.position < len(parser.input) && unicode.IsSpace(parser.input[parser.position]) {
+		parser.position++
+	}
+}
+
+func (parser *expressionParser) accept(char rune) bool {
+	if parser.skipSpaces(); parser.position < len(parser.input) && parser.input[parser.position] == char {
+		parser.position++
+		return true
+	}
+
+	return false
+}
+
+func (parser *expressionParser) parseSum() (expressionNode, error) {
+	node, err := parser.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if parser.accept('+') {
+			node = &binaryNode{operator: '+', left: node}
+		} else if parser.accept('-') {
+			node = &binaryNode{operator: '-', left: node}
+		} else {
+			return node, nil
+		}
+
+		if node.(*binaryNode).right, err = parser.parseProduct(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (parser *expressionParser) parseProduct() (expressionNode, error) {
+	node, err := parser.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if parser.accept('*') {
+			node = &binaryNode{operator: '*', left: node}
+		} else if parser.accept('/') {
+			node = &binaryNode{operator: '/', left: node}
+		} else {
+			return node, nil
+		}
+
+		if node.(*binaryNode).right, err = parser.parseUnary(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (parser *expressionParser) parseUnary() (expressionNode, error) {
+	if parser.accept('-') {
+		operand, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &unaryNode{operand: operand}, nil
+	}
+
+	return parser.parsePrimary()
+}
+
+func (parser *expressionParser) parsePrimary() (expressionNode, error) {
+	if parser.skipSpaces(); parser.position >= len(parser.input) {
+		return nil, parser.errorf("unexpected end of expression")
+	}
+
+	char := parser.input[parser.position]
+
+	switch {
+	case parser.accept('('):
+		node, err := parser.parseSum()
+		if err != nil {
+			return nil, err
+		} else if !parser.accept(')') {
+			return nil, parser.errorf("missing closing parenthesis")
+		}
+
+		return node, nil
+
+	case char == '"':
+		start := parser.position + 1
+
+		for parser.position = start; parser.position < len(parser.input); parser.position++ {
+			if parser.input[parser.position] == '"' {
+				break
+			}
+		}
+
+		if parser.position >= len(parser.input) {
+			return nil, parser.errorf("missing closing quote")
+		}
+
+		name := string(parser.input[start:parser.position])
+		parser.position++
+		parser.variables[name] = true
+
+		return variableNode(name), nil
+
+	case unicode.IsDigit(char) || char == '.':
+		start := parser.position
+
+		for parser.position < len(parser.input) && (unicode.IsDigit(parser.input[parser.position]) ||
+			strings.ContainsRune(".eE", parser.input[parser.position]) || (strings.ContainsRune("+-",
+			parser.input[parser.position]) && strings.ContainsRune("eE", parser.input[parser.position-1]))) {
+			parser.position++
+		}
+
+		value, err := strconv.ParseFloat(string(parser.input[start:parser.position]), 64)
+		if err != nil {
+			return nil, parser.errorf("invalid `%s' number", string(parser.input[start:parser.position]))
+		}
+
+		return constantNode(value), nil
+
+	case unicode.IsLetter(char) || char == '_':
+		start := parser.position
+
+		for parser.position < len(parser.input) && (unicode.IsLetter(parser.input[parser.position]) ||
+			unicode.IsDigit(parser.input[parser.position]) || strings.ContainsRune("_.", parser.input[parser.position])) {
+			parser.position++
+		}
+
+		name := string(parser.input[start:parser.position])
+
+		if (name == "min" || name == "max") && parser.accept('(') {
+			return parser.parseFunction(name)
+		}
+
+		parser.variables[name] = true
+
+		return variableNode(name), nil
+	}
+
+	return nil, parser.errorf("unexpected `%c' character", char)
+}
+
+func (parser *expressionParser) parseFunction(name string) (expressionNode, error) {
+	node := &functionNode{name: name}
+
+	for {
+		arg, err := parser.parseSum()
+		if err != nil {
+			return nil, err
+		}
+
+		node.args = append(node.args, arg)
+
+		if parser.accept(')') {
+			return node, nil
+		} else if !parser.accept(',') {
+			return nil, parser.errorf("expected `,' or `)' in `%s' arguments", name)
+		}
+	}
+}
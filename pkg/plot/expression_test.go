@@ -0,0 +1,65 @@
+package plot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+func Test_ExpressionEvaluate(test *testing.T) {
+	series := map[string][]types.PlotValue{
+		"used":     {25, 50, nan, 10},
+		"total":    {100, 200, 100, 0},
+		"rx bytes": {5, 6, 7},
+	}
+
+	for _, entry := range []struct {
+		input    string
+		expected []types.PlotValue
+	}{
+		{"used / total * 100", []types.PlotValue{25, 25, nan, nan}},
+		{"(total - used) * -1", []types.PlotValue{-75, -150, nan, 10}},
+		{`"rx bytes" - 1.5e1 / 3`, []types.PlotValue{0, 1, 2}},
+		{`min(used, "rx bytes", 20)`, []types.PlotValue{5, 6, nan, nan}},
+		{"max(used, total) + 2 * 3", []types.PlotValue{106, 206, nan, 16}},
+	} {
+		expression, err := ParseExpression(entry.input)
+		if err != nil {
+			test.Fatalf("`%s': %s", entry.input, err)
+		}
+
+		result, err := expression.Evaluate(series)
+		if err != nil {
+			test.Fatalf("`%s': %s", entry.input, err)
+		}
+
+		testPlotsEqual(test, entry.expected, result)
+	}
+}
+
+func Test_ExpressionVariables(test *testing.T) {
+	expression, err := ParseExpression(`b + "a b" * max(b, c.d)`)
+	if err != nil {
+		test.Fatal(err.Error())
+	}
+
+	if expected := []string{"a b", "b", "c.d"}; !reflect.DeepEqual(expression.Variables(), expected) {
+		test.Logf("\nExpected %#v\nbut got  %#v", expected, expression.Variables())
+		test.Fail()
+	}
+
+	if _, err := expression.Evaluate(map[string][]types.PlotValue{"b": {1}}); err == nil {
+		test.Log("\nExpected error on unknown serie\nbut got  none")
+		test.Fail()
+	}
+}
+
+func Test_ExpressionInvalid(test *testing.T) {
+	for _, input := range []string{"", "a +", "(a * 2", `"a`, "a b", "max(a b)", "1..2", "a $ b", "2 * 3"} {
+		if _, err := ParseExpression(input); err == nil {
+			test.Logf("\nExpected error for `%s'\nbut got  none", input)
+			test.Fail()
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package plot
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+const (
+	// FillNull represents a missing data policy keeping missing values as is.
+	FillNull = "null"
+	// FillZero represents a missing data policy replacing missing values with zero.
+	FillZero = "zero"
+	// FillPrevious represents a missing data policy replacing missing values with the previous known one.
+	FillPrevious = "previous"
+	// FillLinear represents a missing data policy interpolating missing values between known ones.
+	FillLinear = "linear"
+	// FillDrop represents a missing data policy discarding missing values, points around them being joined.
+	FillDrop = "drop"
+)
+
+// ValidateFill checks for the missing data policy validity.
+func ValidateFill(policy string) error {
+	switch policy {
+	case "", FillNull, FillZero, FillPrevious, FillLinear, FillDrop:
+		return nil
+	}
+
+	return fmt.Errorf("unknown `%s' missing data policy", policy)
+}
+
+// Fill applies a missing data policy to a set of plots values, returning a new set of plots values. As plots values
+// are evenly spaced, the `drop' policy leaves missing values in place for the drawing to join points around them.
+func Fill(plots []types.PlotValue, policy string) []types.PlotValue {
+	result := append([]types.PlotValue(nil), plots...)
+
+	switch policy {
+	case FillZero:
+		for i, plot := range result {
+			if math.IsNaN(float64(plot)) {
+				result[i] = 0
+			}
+		}
+
+	case FillPrevious:
+		for i := 1; i < len(result); i++ {
+			if math.IsNaN(float64(result[i])) {
+				result[i] = result[i-1]
+			}
+		}
+
+	case FillLinear:
+		previous := -1
+
+		for i, plot := range result {
+			if math.IsNaN(float64(plot)) {
+				continue
+			}
+
+			// Interpolate values between the previous known value and the current one
+			if previous != -1 && i-previous > 1 {
+				delta := (plot - result[previous]) / types.PlotValue(i-previous)
+
+				for j := previous + 1; j < i; j++ {
+					result[j] = result[previous] + delta*types.PlotValue(j-previous)
+				}
+			}
+
+			previous = i
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,26 @@
+package plot
+
+import (
+	"testing"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+func Test_Fill(test *testing.T) {
+	plots := []types.PlotValue{nan, 1, nan, nan, 4, nan}
+
+	for policy, expected := range map[string][]types.PlotValue{
+		FillNull:     {nan, 1, nan, nan, 4, nan},
+		FillZero:     {0, 1, 0, 0, 4, 0},
+		FillPrevious: {nan, 1, 1, 1, 4, 4},
+		FillLinear:   {nan, 1, 2, 3, 4, nan},
+		FillDrop:     {nan, 1, nan, nan, 4, nan},
+	} {
+		testPlotsEqual(test, expected, Fill(plots, policy))
+	}
+
+	if err := ValidateFill("unknown"); err == nil {
+		test.Log("\nExpected error for `unknown' policy\nbut got  none")
+		test.Fail()
+	}
+}
@@ -0,0 +1,352 @@
+package plot
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/facette/facette/pkg/types"
+	"github.com/facette/facette/pkg/utils"
+)
+
+const (
+	// ForecastHoltWinters represents a Holt-Winters triple exponential smoothing forecasting method.
+	ForecastHoltWinters = "holt_winters"
+	// ForecastLinear represents a linear regression forecasting method.
+	ForecastLinear = "linear"
+
+	// DefaultForecastConfidence represents the default confidence level of forecast bands (in percent).
+	DefaultForecastConfidence = 95
+	// MaxForecastRatio represents the maximum number of predicted points relative to the number of original plots
+	// values, bounding the forecasting horizon to a multiple of the plots values time range.
+	MaxForecastRatio = 4
+)
+
+// Forecast represents a plots values forecasting definition.
+type Forecast struct {
+	Method     string   `json:"method"`
+	Horizon    string   `json:"horizon"`
+	Season     string   `json:"season"`
+	Confidence float64  `json:"confidence"`
+	Threshold  *float64 `json:"threshold"`
+}
+
+// Prediction represents the result of a plots values forecasting. Predicted values and bands have the length of the
+// original plots values extended with the predicted points, values prior to the last known one being missing. The
+// time to threshold is expressed in seconds from the end of the original plots values.
+type Prediction struct {
+	Plots           []types.PlotValue
+	Upper           []types.PlotValue
+	Lower           []types.PlotValue
+	TimeToThreshold float64
+}
+
+type forecastModel struct {
+	predict func(ahead int) float64
+	sigma   float64
+	spread  func(ahead int) float64
+}
+
+// Validate checks for the forecasting definition validity.
+func (forecast *Forecast) Validate() error {
+	switch forecast.Method {
+	case "", ForecastHoltWinters, ForecastLinear:
+	default:
+		return fmt.Errorf("unknown `%s' forecasting method", forecast.Method)
+	}
+
+	refTime := time.Now()
+
+	if horizon, err := forecast.HorizonDuration(refTime); err != nil || horizon <= 0 {
+		return fmt.Errorf("invalid `%s' forecasting horizon", forecast.Horizon)
+	}
+
+	if season, err := forecast.seasonDuration(refTime); err != nil || season < 0 {
+		return fmt.Errorf("invalid `%s' forecasting season", forecast.Season)
+	}
+
+	if forecast.Confidence < 0 || forecast.Confidence >= 100 {
+		return fmt.Errorf("invalid `%g' forecasting confidence level", forecast.Confidence)
+	}
+
+	return nil
+}
+
+// HorizonDuration returns the forecasting horizon duration starting at a given time.
+func (forecast *Forecast) HorizonDuration(refTime time.Time) (time.Duration, error) {
+	horizonTime, err := utils.TimeApplyRange(refTime, forecast.Horizon)
+	if err != nil {
+		return 0, err
+	}
+
+	return horizonTime.Sub(refTime), nil
+}
+
+func (forecast *Forecast) seasonDuration(refTime time.Time) (time.Duration, error) {
+	seasonTime, err := utils.TimeApplyRange(refTime, forecast.Season)
+	if err != nil {
+		return 0, err
+	}
+
+	return seasonTime.Sub(refTime), nil
+}
+
+// Predict fits the forecasting model over a set of plots values ending at a given time and spaced by a given step,
+// and predicts the values over the forecasting horizon along with their confidence bands.
+func (forecast *Forecast) Predict(plots []types.PlotValue, endTime time.Time, step time.Duration) (*Prediction,
+	error) {
+
+	if step <= 0 {
+		return nil, fmt.Errorf("invalid forecasting step")
+	}
+
+	horizon, err := forecast.HorizonDuration(endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	season, err := forecast.seasonDuration(endTime)
+	if err != nil {
+		return nil, err
+	}
+
+	points := int(horizon / step)
+	if points > len(plots)*MaxForecastRatio {
+		points = len(plots) * MaxForecastRatio
+	}
+
+	length := len(plots) + points
+
+	prediction := &Prediction{
+		Plots:           make([]types.PlotValue, length),
+		Upper:           make([]types.PlotValue, length),
+		Lower:           make([]types.PlotValue, length),
+		TimeToThreshold: math.NaN(),
+	}
+
+	for i := 0; i < length; i++ {
+		prediction.Plots[i] = types.PlotValue(math.NaN())
+		prediction.Upper[i] = types.PlotValue(math.NaN())
+		prediction.Lower[i] = types.PlotValue(math.NaN())
+	}
+
+	// Only fit the model over the known values range, interpolating missing values in between
+	first, last := -1, -1
+
+	for i, plot := range plots {
+		if !math.IsNaN(float64(plot)) {
+			if first == -1 {
+				first = i
+			}
+
+			last = i
+		}
+	}
+
+	if first == -1 || last-first < 1 {
+		return prediction, nil
+	}
+
+	values := make([]float64, 0)
+	for _, plot := range Fill(plots[first:last+1], FillLinear) {
+		values = append(values, float64(plot))
+	}
+
+	var model *forecastModel
+
+	if forecast.Method == ForecastLinear {
+		model = fitLinear(values)
+	} else {
+		model = fitHoltWinters(values, int(season/step))
+	}
+
+	confidence := forecast.Confidence
+	if confidence == 0 {
+		confidence = DefaultForecastConfidence
+	}
+
+	z := math.Sqrt2 * math.Erfinv(confidence/100)
+
+	// Start predicted values and bands at the last known value for them to join the original plots
+	prediction.Plots[last] = plots[last]
+	prediction.Upper[last] = plots[last]
+	prediction.Lower[last] = plots[last]
+
+	if forecast.Threshold != nil && float64(plots[last]) == *forecast.Threshold {
+		prediction.TimeToThreshold = 0
+	}
+
+	for i := last + 1; i < length; i++ {
+		value := model.predict(i - last)
+		delta := z * model.sigma * model.spread(i-last)
+
+		prediction.Plots[i] = types.PlotValue(value)
+		prediction.Upper[i] = types.PlotValue(value + delta)
+		prediction.Lower[i] = types.PlotValue(value - delta)
+
+		if forecast.Threshold != nil && math.IsNaN(prediction.TimeToThreshold) &&
+			crossesThreshold(float64(plots[last]), value, *forecast.Threshold) {
+			prediction.TimeToThreshold = math.Max(float64(i-len(plots)), 0) * step.Seconds()
+		}
+	}
+
+	return prediction, nil
+}
+
+func crossesThreshold(reference, value, threshold float64) bool {
+	if reference < threshold {
+		return value >= threshold
+	}
+
+	return value <= threshold
+}
+
+func fitLinear(values []float64) *forecastModel {
+	var sumX, sumY, sumXX, sumXY float64
+
+	n := float64(len(values))
+
+	for i, value := range values {
+		x := float64(i)
+
+		sumX += x
+		sumY += value
+		sumXX += x * x
+		sumXY += x * value
+	}
+
+	meanX := sumX / n
+	sxx := sumXX - n*meanX*meanX
+
+	slope := (sumXY - n*meanX*(sumY/n)) / sxx
+	intercept := sumY/n - slope*meanX
+
+	errors := 0.0
+	for i, value := range values {
+		errors += math.Pow(value-(intercept+slope*float64(i)), 2)
+	}
+
+	model := &forecastModel{
+		predict: func(ahead int) float64 {
+			return intercept + slope*float64(len(values)-1+ahead)
+		},
+		spread: func(ahead int) float64 {
+			x := float64(len(values) - 1 + ahead)
+			return math.Sqrt(1 + 1/n + (x-meanX)*(x-meanX)/sxx)
+		},
+	}
+
+	if len(values) > 2 {
+		model.sigma = math.Sqrt(errors / (n - 2))
+	}
+
+	return model
+}
+
+func fitHoltWinters(values []float64, period int) *forecastModel {
+	// Fall back on double exponential smoothing if not enough values are available to fit seasons
+	if period < 2 || len(values) < 2*period {
+		period = 0
+	}
+
+	var (
+		best     *holtWintersState
+		bestSSE  = math.Inf(1)
+		gammas   = []float64{0}
+		smoothes = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}
+	)
+
+	if period > 0 {
+		gammas = smoothes
+	}
+
+	// Select smoothing parameters minimizing the one-step-ahead prediction errors
+	for _, alpha := range smoothes {
+		for _, beta := range smoothes {
+			for _, gamma := range gammas {
+				state := newHoltWintersState(values, period)
+				sse := state.fit(values, alpha, beta, gamma)
+
+				if sse < bestSSE {
+					best, bestSSE = state, sse
+				}
+			}
+		}
+	}
+
+	return &forecastModel{
+		predict: best.predict,
+		sigma:   math.Sqrt(bestSSE / float64(best.count)),
+		spread: func(ahead int) float64 {
+			return math.Sqrt(float64(ahead))
+		},
+	}
+}
+
+type holtWintersState struct {
+	level    float64
+	trend    float64
+	seasons  []float64
+	position int
+	count    int
+}
+
+func newHoltWintersState(values []float64, period int) *holtWintersState {
+	state := &holtWintersState{}
+
+	if period == 0 {
+		state.level, state.trend, state.position = values[0], values[1]-values[0], 1
+		return state
+	}
+
+	// Initialize level and trend from the first two seasons averages (centered on their middle points), and seasonal
+	// components from the first season deviations from them
+	first, second := average(values[:period]), average(values[period:2*period])
+	middle := float64(period-1) / 2
+
+	state.trend = (second - first) / float64(period)
+	state.level = first + state.trend*middle
+	state.seasons = make([]float64, period)
+	state.position = period
+
+	for i := 0; i < period; i++ {
+		state.seasons[i] = values[i] - (first + state.trend*(float64(i)-middle))
+	}
+
+	return state
+}
+
+func (state *holtWintersState) season(index int) float64 {
+	if len(state.seasons) == 0 {
+		return 0
+	}
+
+	return state.seasons[index%len(state.seasons)]
+}
+
+func (state *holtWintersState) fit(values []float64, alpha, beta, gamma float64) float64 {
+	sse := 0.0
+
+	for i := state.position; i < len(values); i++ {
+		season := state.season(i)
+
+		sse += math.Pow(values[i]-(state.level+state.trend+season), 2)
+		state.count++
+
+		level := alpha*(values[i]-season) + (1-alpha)*(state.level+state.trend)
+		state.trend = beta*(level-state.level) + (1-beta)*state.trend
+		state.level = level
+
+		if len(state.seasons) > 0 {
+			state.seasons[i%len(state.seasons)] = gamma*(values[i]-level) + (1-gamma)*season
+		}
+	}
+
+	state.position = len(values)
+
+	return sse
+}
+
+func (state *holtWintersState) predict(ahead int) float64 {
+	return state.level + float64(ahead)*state.trend + state.season(state.position-1+ahead)
+}
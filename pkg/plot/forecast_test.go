@@ -0,0 +1,113 @@
+package plot
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+func Test_ForecastValidate(test *testing.T) {
+	for _, forecast := range []*Forecast{
+		{Horizon: "1d"},
+		{Method: ForecastLinear, Horizon: "2w", Confidence: 99},
+		{Method: ForecastHoltWinters, Horizon: "1d", Season: "1h"},
+	} {
+		if err := forecast.Validate(); err != nil {
+			test.Logf("\nExpected %+v to be valid\nbut got  %s", forecast, err)
+			test.Fail()
+		}
+	}
+
+	for _, forecast := range []*Forecast{
+		{Method: "unknown", Horizon: "1d"},
+		{Horizon: ""},
+		{Horizon: "-1d"},
+		{Horizon: "1d", Season: "abc"},
+		{Horizon: "1d", Confidence: 100},
+	} {
+		if err := forecast.Validate(); err == nil {
+			test.Logf("\nExpected %+v to be invalid", forecast)
+			test.Fail()
+		}
+	}
+}
+
+func Test_ForecastLinear(test *testing.T) {
+	threshold := 12.0
+	forecast := &Forecast{Method: ForecastLinear, Horizon: "5m", Threshold: &threshold}
+
+	prediction, err := forecast.Predict([]types.PlotValue{0, 1, 2, nan, 4, 5, 6, 7, 8, 9}, time.Now(), time.Minute)
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	expected := []types.PlotValue{nan, nan, nan, nan, nan, nan, nan, nan, nan, 9, 10, 11, 12, 13, 14}
+
+	testPlotsEqual(test, expected, prediction.Plots)
+	testPlotsEqual(test, expected, prediction.Upper)
+	testPlotsEqual(test, expected, prediction.Lower)
+
+	if prediction.TimeToThreshold != 120 {
+		test.Logf("\nExpected time to threshold=%g\nbut got  %g", 120.0, prediction.TimeToThreshold)
+		test.Fail()
+	}
+
+	// Check predicted points bounded relatively to the original plots values
+	forecast.Horizon = "1d"
+
+	if prediction, _ = forecast.Predict([]types.PlotValue{0, 1}, time.Now(), time.Minute); len(
+		prediction.Plots) != 2+2*MaxForecastRatio {
+		test.Logf("\nExpected %d plots\nbut got  %d", 2+2*MaxForecastRatio, len(prediction.Plots))
+		test.Fail()
+	}
+
+	// Check threshold not reached within horizon
+	forecast.Horizon = "5m"
+	threshold = 100
+
+	if prediction, _ = forecast.Predict([]types.PlotValue{0, 1, 2}, time.Now(), time.Minute); !math.IsNaN(
+		prediction.TimeToThreshold) {
+		test.Logf("\nExpected time to threshold=NaN\nbut got  %g", prediction.TimeToThreshold)
+		test.Fail()
+	}
+}
+
+func Test_ForecastHoltWinters(test *testing.T) {
+	plots := make([]types.PlotValue, 96)
+
+	for i := range plots {
+		plots[i] = types.PlotValue(10 + float64(i)/10 + 5*math.Sin(2*math.Pi*float64(i)/24))
+	}
+
+	forecast := &Forecast{Method: ForecastHoltWinters, Horizon: "1d", Season: "1d"}
+
+	prediction, err := forecast.Predict(plots, time.Now(), time.Hour)
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	if len(prediction.Plots) != 120 {
+		test.Logf("\nExpected %d points\nbut got  %d", 120, len(prediction.Plots))
+		test.FailNow()
+	}
+
+	// Check the seasonal pattern and trend are followed
+	for i := 96; i < 120; i++ {
+		expected := 10 + float64(i)/10 + 5*math.Sin(2*math.Pi*float64(i)/24)
+
+		if math.Abs(float64(prediction.Plots[i])-expected) > 1 {
+			test.Logf("\nExpected value #%d close to %g\nbut got  %g", i, expected, prediction.Plots[i])
+			test.Fail()
+		}
+
+		if prediction.Lower[i] > prediction.Plots[i] || prediction.Upper[i] < prediction.Plots[i] {
+			test.Logf("\nExpected value #%d within bands\nbut got  %g not in [%g, %g]", i, prediction.Plots[i],
+				prediction.Lower[i], prediction.Upper[i])
+			test.Fail()
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package plot implements the server-side processing of plots values (e.g. transformations, statistics).
+package plot
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+// DefaultPercentiles represents the percentiles computed when none are requested.
+var DefaultPercentiles = []float64{95, 99}
+
+// Summarize computes the information statistics of a set of plots values starting at a given time and spaced by a
+// given step. Along with the requested percentiles, statistics include `min', `max', `avg', `first', `last', `sum',
+// `count', `stddev', `total' (integral over time) and the `min_time' and `max_time' Unix timestamps of extrema.
+func Summarize(plots []types.PlotValue, startTime time.Time, step time.Duration,
+	percentiles []float64) map[string]types.PlotValue {
+
+	var (
+		count              int
+		sum, squares       float64
+		minIndex, maxIndex int
+	)
+
+	info := make(map[string]types.PlotValue)
+
+	min, max, first, last := math.NaN(), math.NaN(), math.NaN(), math.NaN()
+	values := make([]float64, 0)
+
+	for index, plot := range plots {
+		value := float64(plot)
+		if math.IsNaN(value) {
+			continue
+		}
+
+		if count == 0 {
+			first = value
+		}
+
+		if count == 0 || value < min {
+			min, minIndex = value, index
+		}
+
+		if count == 0 || value > max {
+			max, maxIndex = value, index
+		}
+
+		sum += value
+		squares += value * value
+		last = value
+		count++
+
+		values = append(values, value)
+	}
+
+	info["min"] = types.PlotValue(min)
+	info["max"] = types.PlotValue(max)
+	info["first"] = types.PlotValue(first)
+	info["last"] = types.PlotValue(last)
+	info["count"] = types.PlotValue(count)
+
+	if count > 0 {
+		mean := sum / float64(count)
+
+		info["avg"] = types.PlotValue(mean)
+		info["sum"] = types.PlotValue(sum)
+		info["stddev"] = types.PlotValue(math.Sqrt(math.Max(squares/float64(count)-mean*mean, 0)))
+		info["min_time"] = types.PlotValue(plotTime(startTime, step, minIndex))
+		info["max_time"] = types.PlotValue(plotTime(startTime, step, maxIndex))
+	} else {
+		for _, key := range []string{"avg", "sum", "stddev", "min_time", "max_time"} {
+			info[key] = types.PlotValue(math.NaN())
+		}
+	}
+
+	if count > 0 && step > 0 {
+		info["total"] = types.PlotValue(sum * step.Seconds())
+	} else {
+		info["total"] = types.PlotValue(math.NaN())
+	}
+
+	sort.Float64s(values)
+
+	for _, percentile := range percentiles {
+		info[percentileKey(percentile)] = types.PlotValue(percentileValue(values, percentile))
+	}
+
+	return info
+}
+
+func plotTime(startTime time.Time, step time.Duration, index int) float64 {
+	return float64(startTime.Unix()) + step.Seconds()*float64(index)
+}
+
+func percentileKey(percentile float64) string {
+	if percentile-float64(int(percentile)) != 0 {
+		return fmt.Sprintf("%.2fth", percentile)
+	}
+
+	return fmt.Sprintf("%.0fth", percentile)
+}
+
+func percentileValue(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+
+	// Use nearest-rank method
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	} else if rank > len(sorted) {
+		rank = len(sorted)
+	}
+
+	return sorted[rank-1]
+}
+
+// Sum returns the point-by-point sum of several sets of plots values, missing values being ignored.
+func Sum(series ...[]types.PlotValue) []types.PlotValue {
+	return combine(series, false)
+}
+
+// Average returns the point-by-point average of several sets of plots values, missing values being ignored.
+func Average(series ...[]types.PlotValue) []types.PlotValue {
+	return combine(series, true)
+}
+
+func combine(series [][]types.PlotValue, average bool) []types.PlotValue {
+	length := 0
+
+	for _, plots := range series {
+		if len(plots) > length {
+			length = len(plots)
+		}
+	}
+
+	result := make([]types.PlotValue, length)
+
+	for i := range result {
+		count, sum := 0, 0.0
+
+		for _, plots := range series {
+			if i < len(plots) && !math.IsNaN(float64(plots[i])) {
+				sum += float64(plots[i])
+				count++
+			}
+		}
+
+		if count == 0 {
+			result[i] = types.PlotValue(math.NaN())
+		} else if average {
+			result[i] = types.PlotValue(sum / float64(count))
+		} else {
+			result[i] = types.PlotValue(sum)
+		}
+	}
+
+	return result
+}
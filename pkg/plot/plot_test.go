@@ -0,0 +1,67 @@
+package plot
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+var nan = types.PlotValue(math.NaN())
+
+func Test_Summarize(test *testing.T) {
+	info := Summarize([]types.PlotValue{4, nan, 1, 3, 2}, time.Unix(1000, 0), 10*time.Second, []float64{50, 99.5})
+
+	expected := map[string]types.PlotValue{"min": 1, "max": 4, "avg": 2.5, "first": 4, "last": 2, "sum": 10,
+		"count": 4, "stddev": types.PlotValue(math.Sqrt(1.25)), "total": 100, "min_time": 1020, "max_time": 1000,
+		"50th": 2, "99.50th": 4}
+
+	for key, value := range expected {
+		if info[key] != value {
+			test.Logf("\nExpected %s=%g\nbut got  %s=%g", key, value, key, info[key])
+			test.Fail()
+		}
+	}
+
+	info = Summarize([]types.PlotValue{nan, nan}, time.Unix(1000, 0), 10*time.Second, nil)
+
+	if info["count"] != 0 {
+		test.Logf("\nExpected count=0\nbut got  count=%g", info["count"])
+		test.Fail()
+	}
+
+	for _, key := range []string{"min", "max", "avg", "first", "last", "sum", "stddev", "total", "min_time",
+		"max_time"} {
+		if !math.IsNaN(float64(info[key])) {
+			test.Logf("\nExpected %s=NaN\nbut got  %s=%g", key, key, info[key])
+			test.Fail()
+		}
+	}
+}
+
+func Test_Combine(test *testing.T) {
+	a := []types.PlotValue{1, nan, 3, nan}
+	b := []types.PlotValue{3, 2, nan}
+
+	testPlotsEqual(test, []types.PlotValue{4, 2, 3, nan}, Sum(a, b))
+	testPlotsEqual(test, []types.PlotValue{2, 2, 3, nan}, Average(a, b))
+}
+
+func testPlotsEqual(test *testing.T, expected, result []types.PlotValue) {
+	if len(expected) != len(result) {
+		test.Logf("\nExpected %v\nbut got  %v", expected, result)
+		test.Fail()
+		return
+	}
+
+	for i := range expected {
+		if math.IsNaN(float64(expected[i])) && math.IsNaN(float64(result[i])) {
+			continue
+		} else if math.Abs(float64(expected[i]-result[i])) > 1e-9 {
+			test.Logf("\nExpected %v\nbut got  %v", expected, result)
+			test.Fail()
+			return
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package plot
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+const (
+	// TransformDerivative represents a derivative transformation.
+	TransformDerivative = "derivative"
+	// TransformNonNegativeDerivative represents a derivative transformation ignoring negative variations.
+	TransformNonNegativeDerivative = "nonnegative_derivative"
+	// TransformRate represents a per-second rate transformation.
+	TransformRate = "rate"
+	// TransformIntegral represents an integral over time transformation.
+	TransformIntegral = "integral"
+	// TransformMovingAverage represents a moving average over a number of points transformation.
+	TransformMovingAverage = "moving_average"
+	// TransformMovingMedian represents a moving median over a number of points transformation.
+	TransformMovingMedian = "moving_median"
+	// TransformOffset represents a constant offset transformation.
+	TransformOffset = "offset"
+	// TransformAbsolute represents an absolute value transformation.
+	TransformAbsolute = "absolute"
+	// TransformLog represents a logarithmic scale transformation.
+	TransformLog = "log"
+	// TransformCumulativeSum represents a cumulative sum transformation.
+	TransformCumulativeSum = "cumulative_sum"
+
+	// MaxTransformWindow represents the maximum number of points of moving transformations windows.
+	MaxTransformWindow = 10000
+)
+
+// Transform represents a plots values transformation.
+type Transform struct {
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+}
+
+// Validate checks for the transformation definition validity.
+func (transform *Transform) Validate() error {
+	switch transform.Type {
+	case TransformDerivative, TransformNonNegativeDerivative, TransformRate, TransformIntegral, TransformOffset,
+		TransformAbsolute, TransformCumulativeSum:
+
+	case TransformMovingAverage, TransformMovingMedian:
+		if transform.Value < 1 || transform.Value != math.Floor(transform.Value) {
+			return fmt.Errorf("`%s' transformation needs a positive number of points", transform.Type)
+		} else if transform.Value > MaxTransformWindow {
+			return fmt.Errorf("`%s' transformation window exceeds %d points", transform.Type, MaxTransformWindow)
+		}
+
+	case TransformLog:
+		if transform.Value < 0 || transform.Value == 1 {
+			return fmt.Errorf("invalid `%g' logarithm base", transform.Value)
+		}
+
+	default:
+		return fmt.Errorf("unknown `%s' transformation", transform.Type)
+	}
+
+	return nil
+}
+
+// ApplyTransforms applies an ordered list of transformations to a set of plots values spaced by a given step,
+// returning a new set of plots values.
+func ApplyTransforms(plots []types.PlotValue, step time.Duration, transforms []*Transform) []types.PlotValue {
+	result := append([]types.PlotValue(nil), plots...)
+
+	for _, transform := range transforms {
+		if transform == nil {
+			continue
+		}
+
+		result = applyTransform(result, step, transform)
+	}
+
+	return result
+}
+
+func applyTransform(plots []types.PlotValue, step time.Duration, transform *Transform) []types.PlotValue {
+	result := make([]types.PlotValue, len(plots))
+
+	switch transform.Type {
+	case TransformDerivative, TransformNonNegativeDerivative, TransformRate:
+		for i := range plots {
+			if i == 0 {
+				result[i] = types.PlotValue(math.NaN())
+				continue
+			}
+
+			delta := float64(plots[i] - plots[i-1])
+
+			if transform.Type == TransformNonNegativeDerivative && delta < 0 {
+				delta = math.NaN()
+			} else if transform.Type == TransformRate {
+				delta = perSecond(delta, step)
+			}
+
+			result[i] = types.PlotValue(delta)
+		}
+
+	case TransformIntegral, TransformCumulativeSum:
+		sum := 0.0
+
+		for i, plot := range plots {
+			if math.IsNaN(float64(plot)) {
+				result[i] = plot
+				continue
+			}
+
+			if transform.Type == TransformIntegral {
+				sum += float64(plot) * step.Seconds()
+			} else {
+				sum += float64(plot)
+			}
+
+			result[i] = types.PlotValue(sum)
+		}
+
+	case TransformMovingAverage, TransformMovingMedian:
+		window := len(plots)
+		if transform.Value < float64(window) {
+			window = int(transform.Value)
+		}
+
+		for i := range plots {
+			values := make([]float64, 0)
+
+			for j := int(math.Max(0, float64(i-window+1))); j <= i; j++ {
+				if !math.IsNaN(float64(plots[j])) {
+					values = append(values, float64(plots[j]))
+				}
+			}
+
+			if transform.Type == TransformMovingAverage {
+				result[i] = types.PlotValue(average(values))
+			} else {
+				result[i] = types.PlotValue(median(values))
+			}
+		}
+
+	case TransformOffset:
+		for i, plot := range plots {
+			result[i] = plot + types.PlotValue(transform.Value)
+		}
+
+	case TransformAbsolute:
+		for i, plot := range plots {
+			result[i] = types.PlotValue(math.Abs(float64(plot)))
+		}
+
+	case TransformLog:
+		base := transform.Value
+		if base == 0 {
+			base = 10
+		}
+
+		for i, plot := range plots {
+			if plot <= 0 {
+				result[i] = types.PlotValue(math.NaN())
+			} else {
+				result[i] = types.PlotValue(math.Log(float64(plot)) / math.Log(base))
+			}
+		}
+
+	default:
+		copy(result, plots)
+	}
+
+	return result
+}
+
+func perSecond(value float64, step time.Duration) float64 {
+	if step <= 0 {
+		return math.NaN()
+	}
+
+	return value / step.Seconds()
+}
+
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	if len(sorted)%2 == 1 {
+		return sorted[len(sorted)/2]
+	}
+
+	return (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+}
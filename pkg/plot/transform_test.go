@@ -0,0 +1,55 @@
+package plot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/types"
+)
+
+func Test_ApplyTransforms(test *testing.T) {
+	plots := []types.PlotValue{1, 3, 2, nan, 10}
+
+	for _, entry := range []struct {
+		transforms []*Transform
+		expected   []types.PlotValue
+	}{
+		{[]*Transform{{Type: TransformDerivative}}, []types.PlotValue{nan, 2, -1, nan, nan}},
+		{[]*Transform{{Type: TransformNonNegativeDerivative}}, []types.PlotValue{nan, 2, nan, nan, nan}},
+		{[]*Transform{{Type: TransformRate}}, []types.PlotValue{nan, 0.2, -0.1, nan, nan}},
+		{[]*Transform{{Type: TransformIntegral}}, []types.PlotValue{10, 40, 60, nan, 160}},
+		{[]*Transform{{Type: TransformCumulativeSum}}, []types.PlotValue{1, 4, 6, nan, 16}},
+		{[]*Transform{{Type: TransformMovingAverage, Value: 2}}, []types.PlotValue{1, 2, 2.5, 2, 10}},
+		{[]*Transform{{Type: TransformMovingMedian, Value: 3}}, []types.PlotValue{1, 2, 2, 2.5, 6}},
+		{[]*Transform{{Type: TransformMovingAverage, Value: 1e300}}, []types.PlotValue{1, 2, 2, 2, 4}},
+		{[]*Transform{{Type: TransformOffset, Value: -2}}, []types.PlotValue{-1, 1, 0, nan, 8}},
+		{[]*Transform{{Type: TransformOffset, Value: -2}, {Type: TransformAbsolute}},
+			[]types.PlotValue{1, 1, 0, nan, 8}},
+		{[]*Transform{{Type: TransformLog}}, []types.PlotValue{0, 0.47712125472, 0.30102999566, nan, 1}},
+	} {
+		testPlotsEqual(test, entry.expected, ApplyTransforms(plots, 10*time.Second, entry.transforms))
+	}
+
+	// Check original plots are left untouched
+	testPlotsEqual(test, []types.PlotValue{1, 3, 2, nan, 10}, plots)
+}
+
+func Test_TransformValidate(test *testing.T) {
+	for _, entry := range []struct {
+		transform *Transform
+		valid     bool
+	}{
+		{&Transform{Type: TransformRate}, true},
+		{&Transform{Type: TransformMovingAverage, Value: 5}, true},
+		{&Transform{Type: TransformMovingAverage}, false},
+		{&Transform{Type: TransformMovingMedian, Value: 2.5}, false},
+		{&Transform{Type: TransformMovingMedian, Value: MaxTransformWindow + 1}, false},
+		{&Transform{Type: TransformLog, Value: 1}, false},
+		{&Transform{Type: "unknown"}, false},
+	} {
+		if err := entry.transform.Validate(); (err == nil) != entry.valid {
+			test.Logf("\nExpected `%s' validity to be %v\nbut got  %v", entry.transform.Type, entry.valid, err)
+			test.Fail()
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	anchorStart = iota
+	anchorMiddle
+	anchorEnd
+)
+
+type point struct {
+	x, y float64
+}
+
+type canvas interface {
+	fillRect(x, y, width, height float64, c color.RGBA)
+	fillPolygon(points []point, c color.RGBA)
+	drawLine(points []point, c color.RGBA, width float64)
+	drawText(x, y float64, text string, c color.RGBA, anchor int)
+}
+
+// rasterCanvas represents a canvas drawing into an RGBA image.
+type rasterCanvas struct {
+	image *image.RGBA
+}
+
+func newRasterCanvas(width, height int) *rasterCanvas {
+	return &rasterCanvas{image: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+func (canvas *rasterCanvas) blend(x, y int, c color.RGBA) {
+	if !(image.Point{x, y}.In(canvas.image.Rect)) {
+		return
+	}
+
+	offset := canvas.image.PixOffset(x, y)
+	pixel := canvas.image.Pix[offset : offset+4]
+
+	alpha := uint32(c.A)
+	for i, value := range []uint8{c.R, c.G, c.B} {
+		pixel[i] = uint8((uint32(value)*alpha + uint32(pixel[i])*(255-alpha)) / 255)
+	}
+
+	pixel[3] = uint8(alpha + uint32(pixel[3])*(255-alpha)/255)
+}
+
+func (canvas *rasterCanvas) fillRect(x, y, width, height float64, c color.RGBA) {
+	for j := int(math.Floor(y)); j < int(math.Floor(y+height)); j++ {
+		for i := int(math.Floor(x)); i < int(math.Floor(x+width)); i++ {
+			canvas.blend(i, j, c)
+		}
+	}
+}
+
+func (canvas *rasterCanvas) fillPolygon(points []point, c color.RGBA) {
+	if len(points) < 3 {
+		return
+	}
+
+	minY, maxY := points[0].y, points[0].y
+	for _, p := range points {
+		minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+	}
+
+	// Fill polygon using even-odd rule scanlines
+	for y := int(math.Floor(minY)); y <= int(math.Ceil(maxY)); y++ {
+		scanY := float64(y) + 0.5
+		crossings := make([]float64, 0)
+
+		for i := range points {
+			a, b := points[i], points[(i+1)%len(points)]
+			if (a.y <= scanY) == (b.y <= scanY) {
+				continue
+			}
+
+			crossings = append(crossings, a.x+(scanY-a.y)*(b.x-a.x)/(b.y-a.y))
+		}
+
+		sort.Float64s(crossings)
+
+		for i := 0; i+1 < len(crossings); i += 2 {
+			for x := int(math.Ceil(crossings[i] - 0.5)); x < int(math.Ceil(crossings[i+1]-0.5)); x++ {
+				canvas.blend(x, y, c)
+			}
+		}
+	}
+}
+
+func (canvas *rasterCanvas) drawLine(points []point, c color.RGBA, width float64) {
+	size := int(math.Max(1, math.Floor(width+0.5)))
+
+	for i := 0; i+1 < len(points); i++ {
+		a, b := points[i], points[i+1]
+
+		steps := int(math.Max(math.Abs(b.x-a.x), math.Abs(b.y-a.y))) + 1
+		lastX, lastY := math.MinInt32, math.MinInt32
+
+		for step := 0; step <= steps; step++ {
+			ratio := float64(step) / float64(steps)
+			x := int(math.Floor(a.x + (b.x-a.x)*ratio))
+			y := int(math.Floor(a.y + (b.y-a.y)*ratio))
+
+			if x == lastX && y == lastY {
+				continue
+			}
+
+			for j := 0; j < size; j++ {
+				for k := 0; k < size; k++ {
+					canvas.blend(x+k-size/2, y+j-size/2, c)
+				}
+			}
+
+			lastX, lastY = x, y
+		}
+	}
+}
+
+func (canvas *rasterCanvas) drawText(x, y float64, text string, c color.RGBA, anchor int) {
+	switch anchor {
+	case anchorMiddle:
+		x -= textWidth(text) / 2
+	case anchorEnd:
+		x -= textWidth(text)
+	}
+
+	// Text position refers to the baseline
+	originX, originY := int(math.Floor(x)), int(math.Floor(y))-fontHeight
+
+	for index, char := range []rune(text) {
+		glyph := fontGlyph(char)
+
+		for column := 0; column < fontWidth; column++ {
+			for row := 0; row < fontHeight; row++ {
+				if glyph[column]>>uint(row)&1 == 1 {
+					canvas.blend(originX+index*fontAdvance+column, originY+row, c)
+				}
+			}
+		}
+	}
+}
+
+// svgCanvas represents a canvas producing SVG elements.
+type svgCanvas struct {
+	buffer bytes.Buffer
+	width  int
+	height int
+}
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	return &svgCanvas{width: width, height: height}
+}
+
+func (canvas *svgCanvas) fillRect(x, y, width, height float64, c color.RGBA) {
+	fmt.Fprintf(&canvas.buffer, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" %s/>\n", x, y, width,
+		height, svgPaint("fill", c))
+}
+
+func (canvas *svgCanvas) fillPolygon(points []point, c color.RGBA) {
+	if len(points) < 3 {
+		return
+	}
+
+	fmt.Fprintf(&canvas.buffer, "<path d=\"%sZ\" %s/>\n", svgPath(points), svgPaint("fill", c))
+}
+
+func (canvas *svgCanvas) drawLine(points []point, c color.RGBA, width float64) {
+	if len(points) < 2 {
+		return
+	}
+
+	fmt.Fprintf(&canvas.buffer, "<path d=\"%s\" fill=\"none\" stroke-width=\"%.1f\" %s/>\n", svgPath(points), width,
+		svgPaint("stroke", c))
+}
+
+func (canvas *svgCanvas) drawText(x, y float64, text string, c color.RGBA, anchor int) {
+	anchors := map[int]string{anchorStart: "start", anchorMiddle: "middle", anchorEnd: "end"}
+
+	fmt.Fprintf(&canvas.buffer, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"%s\" %s>%s</text>\n", x, y,
+		anchors[anchor], svgPaint("fill", c), html.EscapeString(text))
+}
+
+func (canvas *svgCanvas) bytes() []byte {
+	result := bytes.NewBufferString(fmt.Sprintf("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"+
+		"<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" "+
+		"font-family=\"monospace\" font-size=\"10\" xml:space=\"preserve\">\n", canvas.width, canvas.height,
+		canvas.width, canvas.height))
+
+	result.Write(canvas.buffer.Bytes())
+	result.WriteString("</svg>\n")
+
+	return result.Bytes()
+}
+
+func svgPaint(attribute string, c color.RGBA) string {
+	paint := fmt.Sprintf("%s=\"#%02x%02x%02x\"", attribute, c.R, c.G, c.B)
+
+	if c.A != 255 {
+		paint += fmt.Sprintf(" %s-opacity=\"%.2f\"", attribute, float64(c.A)/255)
+	}
+
+	return paint
+}
+
+func svgPath(points []point) string {
+	chunks := make([]string, len(points))
+
+	for i, p := range points {
+		command := "L"
+		if i == 0 {
+			command = "M"
+		}
+
+		chunks[i] = fmt.Sprintf("%s%.1f %.1f", command, p.x, p.y)
+	}
+
+	return strings.Join(chunks, " ")
+}
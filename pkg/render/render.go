@@ -0,0 +1,561 @@
+// Package render implements the server-side rendering of graphs plots into PNG and SVG images.
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/types"
+)
+
+const (
+	// DefaultWidth represents the default width of rendered images.
+	DefaultWidth = 800
+	// DefaultHeight represents the default height of rendered images.
+	DefaultHeight = 300
+	// MaxSize represents the maximum width or height of rendered images.
+	MaxSize = 4096
+
+	legendLineHeight = 14
+	minPlotHeight    = 40
+)
+
+var (
+	colorBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorText       = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	colorAxis       = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	colorGrid       = color.RGBA{0xe6, 0xe6, 0xe6, 0xff}
+	colorHighlight  = color.RGBA{0xee, 0x33, 0x00, 0x1a}
+
+	// Same default series colors as the browser-side charts
+	colorPalette = []string{"#2f7ed8", "#0d233a", "#8bbc21", "#910000", "#1aadce", "#492970", "#f28f43", "#77a1e5",
+		"#c42525", "#a6c96a"}
+
+	timeSteps = []time.Duration{
+		time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+		time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+		24 * time.Hour, 2 * 24 * time.Hour, 7 * 24 * time.Hour, 14 * 24 * time.Hour, 30 * 24 * time.Hour,
+	}
+)
+
+// Graph represents a graph to be rendered.
+type Graph struct {
+	Title     string
+	Type      int
+	StackMode int
+	Start     time.Time
+	End       time.Time
+	Stacks    []*Stack
+	Intervals []*Interval
+	Message   string
+	Width     int
+	Height    int
+}
+
+// Interval represents a time interval to be highlighted (e.g. anomalous values).
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Stack represents a set of series to be rendered.
+type Stack struct {
+	Name   string
+	Series []*Serie
+}
+
+// Serie represents a serie to be rendered.
+type Serie struct {
+	Name  string
+	Plots []types.PlotValue
+	Info  map[string]types.PlotValue
+	Color string
+}
+
+// RenderPNG renders a graph as a PNG image.
+func RenderPNG(writer io.Writer, graph *Graph) error {
+	width, height, err := graph.size()
+	if err != nil {
+		return err
+	}
+
+	canvas := newRasterCanvas(width, height)
+	graph.draw(canvas, float64(width), float64(height))
+
+	return png.Encode(writer, canvas.image)
+}
+
+// RenderSVG renders a graph as a SVG image.
+func RenderSVG(writer io.Writer, graph *Graph) error {
+	width, height, err := graph.size()
+	if err != nil {
+		return err
+	}
+
+	canvas := newSVGCanvas(width, height)
+	graph.draw(canvas, float64(width), float64(height))
+
+	_, err = writer.Write(canvas.bytes())
+
+	return err
+}
+
+type renderSerie struct {
+	*Serie
+	color        color.RGBA
+	lower, upper []float64
+}
+
+func (graph *Graph) size() (int, int, error) {
+	width, height := graph.Width, graph.Height
+
+	if width == 0 {
+		width = DefaultWidth
+	}
+
+	if height == 0 {
+		height = DefaultHeight
+	}
+
+	if width < 0 || width > MaxSize || height < 0 || height > MaxSize {
+		return 0, 0, fmt.Errorf("image size must be between 1 and %d", MaxSize)
+	}
+
+	// Ensure legend and plot area fit in the image
+	if minHeight := graph.layoutHeight() + minPlotHeight; height < minHeight {
+		height = minHeight
+	}
+
+	return width, height, nil
+}
+
+func (graph *Graph) layoutTop() float64 {
+	if graph.Title != "" {
+		return 30
+	}
+
+	return 12
+}
+
+func (graph *Graph) layoutHeight() int {
+	count := 0
+
+	for _, stack := range graph.Stacks {
+		count += len(stack.Series)
+	}
+
+	return int(graph.layoutTop()) + 24 + count*legendLineHeight + 6
+}
+
+func (graph *Graph) prepareSeries() []*renderSerie {
+	result := make([]*renderSerie, 0)
+
+	for _, stack := range graph.Stacks {
+		var base, totals []float64
+
+		series := make([]*Serie, len(stack.Series))
+		copy(series, stack.Series)
+
+		sort.Sort(seriesByName(series))
+
+		count := 0
+		for _, serie := range series {
+			if len(serie.Plots) > count {
+				count = len(serie.Plots)
+			}
+		}
+
+		if graph.StackMode != library.StackModeNone {
+			base = make([]float64, count)
+		}
+
+		if graph.StackMode == library.StackModePercent {
+			totals = make([]float64, count)
+
+			for _, serie := range series {
+				for i, value := range serie.Plots {
+					if !math.IsNaN(float64(value)) {
+						totals[i] += float64(value)
+					}
+				}
+			}
+		}
+
+		for _, serie := range series {
+			item := &renderSerie{
+				Serie: serie,
+				lower: make([]float64, count),
+				upper: make([]float64, count),
+			}
+
+			if c, ok := parseColor(serie.Color); ok {
+				item.color = c
+			} else {
+				item.color, _ = parseColor(colorPalette[len(result)%len(colorPalette)])
+			}
+
+			for i := 0; i < count; i++ {
+				value := math.NaN()
+				if i < len(serie.Plots) {
+					value = float64(serie.Plots[i])
+				}
+
+				if graph.StackMode == library.StackModePercent {
+					if totals[i] != 0 {
+						value = value / totals[i] * 100
+					} else {
+						value = math.NaN()
+					}
+				}
+
+				if base == nil {
+					item.lower[i], item.upper[i] = 0, value
+					continue
+				}
+
+				item.lower[i], item.upper[i] = base[i], math.NaN()
+
+				if !math.IsNaN(value) {
+					base[i] += value
+					item.upper[i] = base[i]
+				}
+			}
+
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+func (graph *Graph) draw(canvas canvas, width, height float64) {
+	series := graph.prepareSeries()
+
+	// Compute values range
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+
+	for _, serie := range series {
+		for i := range serie.upper {
+			if math.IsNaN(serie.upper[i]) {
+				continue
+			}
+
+			yMin, yMax = math.Min(yMin, serie.upper[i]), math.Max(yMax, serie.upper[i])
+
+			if graph.Type == library.GraphTypeArea || graph.StackMode != library.StackModeNone {
+				yMin, yMax = math.Min(yMin, serie.lower[i]), math.Max(yMax, serie.lower[i])
+			}
+		}
+	}
+
+	if graph.StackMode == library.StackModePercent {
+		yMin, yMax = 0, 100
+	} else if math.IsInf(yMin, 0) {
+		yMin, yMax = 0, 1
+	}
+
+	top := graph.layoutTop()
+	bottom := height - float64(graph.layoutHeight()) + top
+
+	yTicks := valueTicks(yMin, yMax, int((bottom-top)/40))
+	yMin, yMax = yTicks[0], yTicks[len(yTicks)-1]
+
+	left := 10.0
+	for _, tick := range yTicks {
+		left = math.Max(left, textWidth(formatValue(tick))+16)
+	}
+
+	right := width - 15
+
+	scaleX := func(index, count int) float64 {
+		if count < 2 {
+			return (left + right) / 2
+		}
+
+		return left + (right-left)*float64(index)/float64(count-1)
+	}
+
+	scaleY := func(value float64) float64 {
+		return bottom - (bottom-top)*(value-yMin)/(yMax-yMin)
+	}
+
+	canvas.fillRect(0, 0, width, height, colorBackground)
+
+	if graph.Title != "" {
+		canvas.drawText(width/2, 20, graph.Title, colorText, anchorMiddle)
+	}
+
+	// Draw grid and axes labels
+	for _, tick := range yTicks {
+		y := math.Floor(scaleY(tick)) + 0.5
+
+		canvas.drawLine([]point{{left, y}, {right, y}}, colorGrid, 1)
+		canvas.drawText(left-6, y+3, formatValue(tick), colorText, anchorEnd)
+	}
+
+	duration := graph.End.Sub(graph.Start)
+
+	if duration > 0 {
+		step, layout := timeStep(duration, int((right-left)/100))
+
+		for tick := graph.Start.Truncate(step); !tick.After(graph.End); tick = tick.Add(step) {
+			if tick.Before(graph.Start) {
+				continue
+			}
+
+			x := math.Floor(left+(right-left)*float64(tick.Sub(graph.Start))/float64(duration)) + 0.5
+
+			canvas.drawLine([]point{{x, top}, {x, bottom}}, colorGrid, 1)
+			canvas.drawText(x, bottom+14, tick.Format(layout), colorText, anchorMiddle)
+		}
+	}
+
+	// Draw highlighted intervals
+	if duration > 0 {
+		for _, interval := range graph.Intervals {
+			start := math.Max(0, float64(interval.Start.Sub(graph.Start))/float64(duration))
+			end := math.Min(1, float64(interval.End.Sub(graph.Start))/float64(duration))
+
+			if end > start {
+				canvas.fillRect(left+(right-left)*start, top, (right-left)*(end-start), bottom-top, colorHighlight)
+			}
+		}
+	}
+
+	canvas.drawLine([]point{{left, top}, {left, bottom}, {right, bottom}}, colorAxis, 1)
+
+	// Draw placeholder message (e.g. when no data is available)
+	if graph.Message != "" {
+		canvas.drawText((left+right)/2, (top+bottom)/2, graph.Message, colorText, anchorMiddle)
+	}
+
+	// Draw series
+	for _, serie := range series {
+		for _, segment := range plotSegments(serie.upper) {
+			upper := make([]point, 0)
+			lower := make([]point, 0)
+
+			for i := segment[0]; i < segment[1]; i++ {
+				x := scaleX(i, len(serie.upper))
+
+				upper = append(upper, point{x, scaleY(serie.upper[i])})
+				lower = append([]point{{x, scaleY(math.Max(yMin, math.Min(yMax, serie.lower[i])))}}, lower...)
+			}
+
+			if graph.Type == library.GraphTypeArea {
+				fill := serie.color
+				fill.A = 0x60
+
+				canvas.fillPolygon(append(upper, lower...), fill)
+			}
+
+			canvas.drawLine(upper, serie.color, 1.5)
+		}
+	}
+
+	// Draw legend
+	nameWidth := 0.0
+	for _, serie := range series {
+		nameWidth = math.Max(nameWidth, textWidth(serie.Name))
+	}
+
+	for i, serie := range series {
+		y := bottom + 24 + float64(i*legendLineHeight)
+
+		canvas.fillRect(left, y, 8, 8, serie.color)
+		canvas.drawText(left+14, y+8, serie.Name, colorText, anchorStart)
+
+		info := serie.summary()
+		chunks := make([]string, 0)
+
+		for _, key := range []string{"min", "avg", "max", "last"} {
+			chunks = append(chunks, fmt.Sprintf("%s: %-8s", key, formatValue(info[key])))
+		}
+
+		canvas.drawText(left+nameWidth+30, y+8, strings.Join(chunks, " "), colorText, anchorStart)
+	}
+}
+
+func (serie *renderSerie) summary() map[string]float64 {
+	result := map[string]float64{
+		"min":  math.NaN(),
+		"avg":  math.NaN(),
+		"max":  math.NaN(),
+		"last": math.NaN(),
+	}
+
+	count, sum := 0, 0.0
+
+	for _, value := range serie.Plots {
+		if math.IsNaN(float64(value)) {
+			continue
+		}
+
+		if count == 0 || float64(value) < result["min"] {
+			result["min"] = float64(value)
+		}
+
+		if count == 0 || float64(value) > result["max"] {
+			result["max"] = float64(value)
+		}
+
+		result["last"] = float64(value)
+		sum += float64(value)
+		count++
+	}
+
+	if count > 0 {
+		result["avg"] = sum / float64(count)
+	}
+
+	// Prefer connector provided information if any
+	for key := range result {
+		if value, ok := serie.Info[key]; ok {
+			result[key] = float64(value)
+		}
+	}
+
+	return result
+}
+
+func plotSegments(values []float64) [][2]int {
+	segments := make([][2]int, 0)
+	start := -1
+
+	for i := 0; i <= len(values); i++ {
+		if i < len(values) && !math.IsNaN(values[i]) {
+			if start == -1 {
+				start = i
+			}
+		} else if start != -1 {
+			segments = append(segments, [2]int{start, i})
+			start = -1
+		}
+	}
+
+	return segments
+}
+
+func valueTicks(min, max float64, count int) []float64 {
+	if count < 2 {
+		count = 2
+	}
+
+	if max == min {
+		if min == 0 {
+			max = 1
+		} else {
+			min, max = min-math.Abs(min)/2, max+math.Abs(max)/2
+		}
+	}
+
+	step := niceNumber((max - min) / float64(count))
+
+	// Widen the range if its step is lost in large values precision
+	if min+step == min || max+step == max {
+		min, max = min-math.Abs(min)/2, max+math.Abs(max)/2
+		step = niceNumber((max - min) / float64(count))
+	}
+
+	result := []float64{math.Floor(min/step) * step}
+	for (result[len(result)-1] < max-step*1e-9 || len(result) < 2) && len(result) < count*3 {
+		result = append(result, result[len(result)-1]+step)
+	}
+
+	return result
+}
+
+func niceNumber(value float64) float64 {
+	exponent := math.Floor(math.Log10(value))
+	fraction := value / math.Pow(10, exponent)
+
+	switch {
+	case fraction <= 1:
+		fraction = 1
+	case fraction <= 2:
+		fraction = 2
+	case fraction <= 5:
+		fraction = 5
+	default:
+		fraction = 10
+	}
+
+	return fraction * math.Pow(10, exponent)
+}
+
+func timeStep(duration time.Duration, count int) (time.Duration, string) {
+	step := timeSteps[len(timeSteps)-1]
+
+	for _, item := range timeSteps {
+		if count > 0 && duration/item <= time.Duration(count) {
+			step = item
+			break
+		}
+	}
+
+	if step >= 24*time.Hour {
+		return step, "01/02"
+	} else if duration > 24*time.Hour {
+		return step, "01/02 15:04"
+	}
+
+	return step, "15:04"
+}
+
+func formatValue(value float64) string {
+	if math.IsNaN(value) {
+		return "-"
+	}
+
+	units := []string{"", "k", "M", "G", "T", "P", "E", "Z", "Y"}
+	index := 0
+
+	for math.Abs(value) >= 1000 && index < len(units)-1 {
+		value /= 1000
+		index++
+	}
+
+	return strconv.FormatFloat(math.Floor(value*100+0.5)/100, 'f', -1, 64) + units[index]
+}
+
+func parseColor(value string) (color.RGBA, bool) {
+	var r, g, b uint8
+
+	if len(value) == 4 && value[0] == '#' {
+		if _, err := fmt.Sscanf(value, "#%1x%1x%1x", &r, &g, &b); err != nil {
+			return color.RGBA{}, false
+		}
+
+		return color.RGBA{r * 17, g * 17, b * 17, 0xff}, true
+	} else if len(value) == 7 && value[0] == '#' {
+		if _, err := fmt.Sscanf(value, "#%02x%02x%02x", &r, &g, &b); err != nil {
+			return color.RGBA{}, false
+		}
+
+		return color.RGBA{r, g, b, 0xff}, true
+	}
+
+	return color.RGBA{}, false
+}
+
+type seriesByName []*Serie
+
+func (s seriesByName) Len() int {
+	return len(s)
+}
+
+func (s seriesByName) Less(i, j int) bool {
+	return s[i].Name < s[j].Name
+}
+
+func (s seriesByName) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
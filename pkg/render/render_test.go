@@ -0,0 +1,130 @@
+package render
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/types"
+)
+
+func Test_RenderPNG(test *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+
+	if err := RenderPNG(buffer, testGraph(library.StackModeNormal)); err != nil {
+		test.Fatal(err.Error())
+	}
+
+	image, err := png.Decode(buffer)
+	if err != nil {
+		test.Fatal(err.Error())
+	}
+
+	if size := image.Bounds().Size(); size.X != 640 || size.Y != 240 {
+		test.Logf("\nExpected %dx%d\nbut got  %dx%d", 640, 240, size.X, size.Y)
+		test.Fail()
+	}
+}
+
+func Test_RenderSVG(test *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+
+	if err := RenderSVG(buffer, testGraph(library.StackModeNone)); err != nil {
+		test.Fatal(err.Error())
+	}
+
+	for _, expected := range []string{"<svg ", "serie0", "serie1", "min: 1", "last: 4", "</svg>"} {
+		if !strings.Contains(buffer.String(), expected) {
+			test.Logf("\nExpected `%s' in SVG output", expected)
+			test.Fail()
+		}
+	}
+}
+
+func Test_RenderMessage(test *testing.T) {
+	buffer := bytes.NewBuffer(nil)
+
+	if err := RenderSVG(buffer, &Graph{Title: "graph0", Message: "No data"}); err != nil {
+		test.Fatal(err.Error())
+	}
+
+	if !strings.Contains(buffer.String(), "No data") {
+		test.Logf("\nExpected `%s' in SVG output", "No data")
+		test.Fail()
+	}
+}
+
+func Test_RenderStackModes(test *testing.T) {
+	for _, entry := range []struct {
+		stackMode int
+		upper     []float64
+	}{
+		{library.StackModeNone, []float64{4, 3, math.NaN(), 1}},
+		{library.StackModeNormal, []float64{5, 5, math.NaN(), 5}},
+		{library.StackModePercent, []float64{100, 100, math.NaN(), 100}},
+	} {
+		series := testGraph(entry.stackMode).prepareSeries()
+
+		for i, value := range series[1].upper {
+			if math.IsNaN(value) != math.IsNaN(entry.upper[i]) ||
+				!math.IsNaN(value) && math.Abs(value-entry.upper[i]) > 1e-9 {
+				test.Logf("\nExpected %v\nbut got  %v (stack mode: %d)", entry.upper, series[1].upper,
+					entry.stackMode)
+				test.Fail()
+				break
+			}
+		}
+	}
+}
+
+func Test_RenderLargeValues(test *testing.T) {
+	graph := testGraph(library.StackModeNone)
+	graph.Type = library.GraphTypeLine
+	graph.Stacks[0].Series = []*Serie{
+		&Serie{Name: "serie0", Plots: []types.PlotValue{1e9, types.PlotValue(math.Nextafter(1e9, 2e9))}},
+	}
+
+	if err := RenderSVG(bytes.NewBuffer(nil), graph); err != nil {
+		test.Fatal(err.Error())
+	}
+
+	if ticks := valueTicks(1e9, math.Nextafter(1e9, 2e9), 5); len(ticks) < 2 || len(ticks) > 15 {
+		test.Logf("\nExpected between %d and %d ticks\nbut got  %d", 2, 15, len(ticks))
+		test.Fail()
+	}
+}
+
+func Test_RenderSize(test *testing.T) {
+	graph := testGraph(library.StackModeNone)
+	graph.Width = MaxSize + 1
+
+	if err := RenderPNG(bytes.NewBuffer(nil), graph); err == nil {
+		test.Logf("\nExpected error for %d width", graph.Width)
+		test.Fail()
+	}
+}
+
+func testGraph(stackMode int) *Graph {
+	end := time.Date(2014, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	return &Graph{
+		Title:     "graph0",
+		Type:      library.GraphTypeArea,
+		StackMode: stackMode,
+		Start:     end.Add(-time.Hour),
+		End:       end,
+		Width:     640,
+		Height:    240,
+		Stacks: []*Stack{&Stack{
+			Name: "stack0",
+			Series: []*Serie{
+				&Serie{Name: "serie0", Plots: []types.PlotValue{1, 2, 3, 4}},
+				&Serie{Name: "serie1", Plots: []types.PlotValue{4, 3, types.PlotValue(math.NaN()), 1}},
+			},
+		}},
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facette/facette/pkg/alert"
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/types"
+	"github.com/facette/facette/pkg/utils"
+	"github.com/facette/facette/thirdparty/github.com/fatih/set"
+)
+
+func (server *Server) handleAlerts(writer http.ResponseWriter, request *http.Request) {
+	setHTTPCacheHeaders(writer)
+
+	alertID := strings.TrimPrefix(request.URL.Path, urlAlertsPath)
+
+	if alertID == "" {
+		server.handleAlertStateList(writer, request)
+		return
+	}
+
+	if response, status := server.parseShowRequest(writer, request); status != http.StatusOK {
+		server.handleResponse(writer, response, status)
+		return
+	}
+
+	state := server.Alerts.State(alertID)
+	if state == nil {
+		server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+		return
+	}
+
+	server.handleResponse(writer, getAlertResponse(state), http.StatusOK)
+}
+
+func (server *Server) handleAlertStateList(writer http.ResponseWriter, request *http.Request) {
+	var offset, limit int
+
+	if response, status := server.parseListRequest(writer, request, &offset, &limit); status != http.StatusOK {
+		server.handleResponse(writer, response, status)
+		return
+	}
+
+	stateSet := set.New()
+	for _, state := range request.Form["state"] {
+		stateSet.Add(state)
+	}
+
+	// Fill alerts states list
+	items := make(AlertListResponse, 0)
+
+	for _, state := range server.Alerts.States() {
+		if request.FormValue("filter") != "" && !utils.FilterMatch(request.FormValue("filter"), state.Name) {
+			continue
+		}
+
+		if stateSet.Size() > 0 && !stateSet.Has(state.State) {
+			continue
+		}
+
+		items = append(items, getAlertResponse(state))
+	}
+
+	response := &listResponse{
+		list:   items,
+		offset: offset,
+		limit:  limit,
+	}
+
+	server.applyResponseLimit(writer, request, response)
+
+	server.handleResponse(writer, response.list, http.StatusOK)
+}
+
+func (server *Server) handleNotifications(writer http.ResponseWriter, request *http.Request) {
+	var offset, limit int
+
+	setHTTPCacheHeaders(writer)
+
+	if response, status := server.parseListRequest(writer, request, &offset, &limit); status != http.StatusOK {
+		server.handleResponse(writer, response, status)
+		return
+	}
+
+	// Fill notifications history list
+	items := make(NotificationListResponse, 0)
+
+	for _, record := range server.Notifier.History(request.FormValue("alert")) {
+		items = append(items, &NotificationResponse{
+			Alert:    record.Alert,
+			Name:     record.Name,
+			Channel:  record.Channel,
+			State:    record.State,
+			Previous: record.Previous,
+			Serie:    record.Serie,
+			Value:    record.Value,
+			Message:  record.Message,
+			Repeat:   record.Repeat,
+			Flapping: record.Flapping,
+			Error:    record.Error,
+			Time:     record.Time.Format(time.RFC3339),
+		})
+	}
+
+	response := &listResponse{
+		list:   items,
+		offset: offset,
+		limit:  limit,
+	}
+
+	server.applyResponseLimit(writer, request, response)
+
+	server.handleResponse(writer, response.list, http.StatusOK)
+}
+
+func (server *Server) fetchAlertInfo(rule *library.Alert, startTime,
+	endTime time.Time) (map[string]map[string]types.PlotValue, error) {
+
+	plotReq := &PlotRequest{
+		Start:      startTime.Format(time.RFC3339),
+		End:        endTime.Format(time.RFC3339),
+		Graph:      rule.Graph,
+		Origin:     rule.Origin,
+		Source:     rule.Source,
+		Metric:     rule.Metric,
+		Attributes: rule.Attributes,
+	}
+
+	// Request the percentile the rule is evaluated on if any
+	if strings.HasSuffix(rule.GetStatistic(), "th") {
+		if percentile, err := strconv.ParseFloat(strings.TrimSuffix(rule.GetStatistic(), "th"), 64); err == nil {
+			plotReq.Percentiles = []float64{percentile}
+		}
+	}
+
+	response, errResponse, _ := server.getPlots(plotReq)
+	if errResponse != nil {
+		return nil, fmt.Errorf("%s", errResponse.Message)
+	}
+
+	result := make(map[string]map[string]types.PlotValue)
+
+	for _, stack := range response.Stacks {
+		for _, serie := range stack.Series {
+			if serie.Comparison || serie.Forecast || serie.Anomaly {
+				continue
+			}
+
+			result[serie.Name] = serie.Info
+		}
+	}
+
+	return result, nil
+}
+
+func getAlertResponse(state *alert.State) *AlertResponse {
+	response := &AlertResponse{
+		ID:      state.ID,
+		Name:    state.Name,
+		State:   state.State,
+		Serie:   state.Serie,
+		Value:   state.Value,
+		Message: state.Message,
+		Since:   state.Since.Format(time.RFC3339),
+	}
+
+	if !state.Checked.IsZero() {
+		response.Checked = state.Checked.Format(time.RFC3339)
+	}
+
+	return response
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/facette/facette/pkg/annotation"
+	"github.com/facette/facette/pkg/connector"
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/utils"
+)
+
+func (server *Server) handleAnnotations(writer http.ResponseWriter, request *http.Request) {
+	setHTTPCacheHeaders(writer)
+
+	annotationID := strings.TrimPrefix(request.URL.Path, urlAnnotationsPath)
+
+	switch request.Method {
+	case "DELETE":
+		if annotationID == "" {
+			server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+			return
+		} else if !server.handleAuth(writer, request) {
+			server.handleResponse(writer, serverResponse{mesgAuthenticationRequired}, http.StatusUnauthorized)
+			return
+		}
+
+		err := server.Annotations.Delete(annotationID)
+		if os.IsNotExist(err) {
+			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+			return
+		}
+
+		server.handleResponse(writer, nil, http.StatusOK)
+
+	case "GET", "HEAD":
+		if annotationID == "" {
+			server.handleAnnotationList(writer, request)
+			return
+		}
+
+		item, err := server.Annotations.Get(annotationID)
+		if os.IsNotExist(err) {
+			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+			return
+		}
+
+		server.handleResponse(writer, getAnnotationResponse(item), http.StatusOK)
+
+	case "POST", "PUT":
+		if response, status := server.parseStoreRequest(writer, request, annotationID); status != http.StatusOK {
+			server.handleResponse(writer, response, status)
+			return
+		}
+
+		// Parse input JSON for annotation data
+		body, _ := ioutil.ReadAll(request.Body)
+
+		item := &annotation.Annotation{}
+
+		if err := json.Unmarshal(body, item); err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+			return
+		}
+
+		item.ID = annotationID
+		item.Modified = time.Now()
+
+		// Store annotation data
+		err := server.Annotations.Store(item)
+		if response, status := server.parseError(writer, request, err); status != http.StatusOK {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, response, status)
+			return
+		}
+
+		if request.Method == "POST" {
+			writer.Header().Add("Location", strings.TrimRight(request.URL.Path, "/")+"/"+item.ID)
+			server.handleResponse(writer, nil, http.StatusCreated)
+		} else {
+			server.handleResponse(writer, nil, http.StatusOK)
+		}
+
+	default:
+		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+	}
+}
+
+func (server *Server) handleAnnotationList(writer http.ResponseWriter, request *http.Request) {
+	var (
+		offset, limit      int
+		startTime, endTime time.Time
+		err                error
+	)
+
+	if response, status := server.parseListRequest(writer, request, &offset, &limit); status != http.StatusOK {
+		server.handleResponse(writer, response, status)
+		return
+	}
+
+	// Parse time range boundaries
+	for _, entry := range []struct {
+		name  string
+		value *time.Time
+	}{
+		{"start", &startTime},
+		{"end", &endTime},
+	} {
+		if request.FormValue(entry.name) == "" {
+			continue
+		}
+
+		if *entry.value, err = utils.TimeParse(time.Now(), request.FormValue(entry.name)); err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgTimeRangeInvalid}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Fill annotations list
+	items := make(AnnotationListResponse, 0)
+
+	for _, item := range server.Annotations.List(startTime, endTime, request.Form["tag"]) {
+		if request.FormValue("origin") != "" && item.Origin != "" && item.Origin != request.FormValue("origin") ||
+			request.FormValue("source") != "" && item.Source != "" && item.Source != request.FormValue("source") {
+			continue
+		}
+
+		if request.FormValue("filter") != "" && !utils.FilterMatch(request.FormValue("filter"), item.Title) {
+			continue
+		}
+
+		items = append(items, getAnnotationResponse(item))
+	}
+
+	response := &listResponse{
+		list:   items,
+		offset: offset,
+		limit:  limit,
+	}
+
+	server.applyResponseLimit(writer, request, response)
+
+	server.handleResponse(writer, response.list, http.StatusOK)
+}
+
+func (server *Server) getPlotAnnotations(plotReq *PlotRequest, graph *library.Graph, startTime,
+	endTime time.Time) []*AnnotationResponse {
+
+	// Get graph series sources
+	sources := make(map[[2]string]bool)
+
+	for _, stack := range graph.Stacks {
+		for _, group := range stack.Groups {
+			for _, serie := range group.Series {
+				if plotReq.Template != "" {
+					sources[[2]string{serie.Origin, plotReq.Source}] = true
+				} else if strings.HasPrefix(serie.Source, library.LibraryGroupPrefix) {
+					for _, source := range server.Library.ExpandGroup(strings.TrimPrefix(serie.Source,
+						library.LibraryGroupPrefix), library.LibraryItemSourceGroup) {
+						sources[[2]string{serie.Origin, source}] = true
+					}
+				} else {
+					sources[[2]string{serie.Origin, serie.Source}] = true
+				}
+			}
+		}
+	}
+
+	items := make([]*annotation.Annotation, 0)
+
+	for _, item := range server.Annotations.List(startTime, endTime, plotReq.AnnotationTags) {
+		for source := range sources {
+			if item.Matches(source[0], source[1]) {
+				items = append(items, item)
+				break
+			}
+		}
+	}
+
+	// Append events provided by the origins connectors
+	origins := make(map[string]bool)
+	for source := range sources {
+		origins[source[0]] = true
+	}
+
+	for originName := range origins {
+		origin, ok := server.Catalog.Origins[originName]
+		if !ok {
+			continue
+		}
+
+		eventsConnector, ok := origin.Connector.(connector.EventsConnector)
+		if !ok {
+			continue
+		}
+
+		events, err := server.getCachedEvents(originName, eventsConnector, startTime, endTime)
+		if err != nil {
+			log.Printf("ERROR: unable to fetch events from `%s' origin: %s", originName, err)
+			continue
+		}
+
+		for _, event := range events {
+			if event.Time.Before(startTime) || event.Time.After(endTime) {
+				continue
+			}
+
+			item := &annotation.Annotation{
+				ID:       event.ID,
+				Start:    event.Time,
+				Title:    event.Title,
+				Text:     event.Text,
+				Tags:     event.Tags,
+				Origin:   originName,
+				Modified: event.Time,
+			}
+
+			if !item.HasTags(plotReq.AnnotationTags) {
+				continue
+			}
+
+			items = append(items, item)
+		}
+	}
+
+	annotation.Sort(items)
+
+	result := make([]*AnnotationResponse, len(items))
+	for i, item := range items {
+		result[i] = getAnnotationResponse(item)
+	}
+
+	return result
+}
+
+func (server *Server) getCachedEvents(originName string, eventsConnector connector.EventsConnector, startTime,
+	endTime time.Time) ([]*connector.Event, error) {
+
+	var ttl time.Duration
+
+	if originConfig, ok := server.Config.Origins[originName]; ok {
+		ttl = time.Duration(originConfig.CacheTTL) * time.Second
+	}
+
+	if ttl <= 0 {
+		return eventsConnector.GetEvents(startTime, endTime)
+	}
+
+	// Round time boundaries to the cache TTL so that close requests share the same entry, the events being filtered
+	// against the requested time range afterwards
+	startTime, endTime = startTime.Truncate(ttl), endTime.Truncate(ttl).Add(ttl)
+
+	key, err := json.Marshal([]interface{}{"events", originName, startTime.Unix(), endTime.Unix()})
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := server.Cache.Get(string(key), ttl, func() (interface{}, error) {
+		return eventsConnector.GetEvents(startTime, endTime)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value.([]*connector.Event), nil
+}
+
+func getAnnotationResponse(item *annotation.Annotation) *AnnotationResponse {
+	response := &AnnotationResponse{
+		ID:       item.ID,
+		Start:    item.Start.Format(time.RFC3339),
+		Title:    item.Title,
+		Text:     item.Text,
+		Tags:     item.Tags,
+		Origin:   item.Origin,
+		Source:   item.Source,
+		Modified: item.Modified.Format(time.RFC3339),
+	}
+
+	if item.End != nil {
+		response.End = item.End.Format(time.RFC3339)
+	}
+
+	return response
+}
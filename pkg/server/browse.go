@@ -1,15 +1,21 @@
 package server
 
 import (
+	"encoding/json"
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
 	"os"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/facette/facette/pkg/catalog"
 	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/utils"
 )
 
 func (server *Server) handleBrowse(writer http.ResponseWriter, request *http.Request) {
@@ -39,6 +45,12 @@ func (server *Server) handleBrowse(writer http.ResponseWriter, request *http.Req
 	if strings.HasPrefix(request.URL.Path, urlBrowsePath+"collections/") ||
 		strings.HasPrefix(request.URL.Path, urlBrowsePath+"sources/") {
 		err = server.handleBrowseCollection(writer, request, tmpl)
+	} else if strings.HasPrefix(request.URL.Path, urlBrowsePath+"kiosk/") {
+		err = server.handleBrowseKiosk(writer, request, tmpl)
+	} else if strings.HasPrefix(request.URL.Path, urlBrowsePath+"snapshots/") {
+		err = server.handleBrowseSnapshot(writer, request, tmpl)
+	} else if strings.HasPrefix(request.URL.Path, urlBrowsePath+"tags/") {
+		err = server.handleBrowseTags(writer, request, tmpl)
 	} else if request.URL.Path == urlBrowsePath+"search" {
 		err = server.handleBrowseSearch(writer, request, tmpl)
 	} else if request.URL.Path == urlBrowsePath {
@@ -47,7 +59,9 @@ func (server *Server) handleBrowse(writer http.ResponseWriter, request *http.Req
 		err = os.ErrNotExist
 	}
 
-	if os.IsNotExist(err) {
+	if err == os.ErrInvalid {
+		server.handleError(writer, http.StatusBadRequest)
+	} else if os.IsNotExist(err) {
 		server.handleError(writer, http.StatusNotFound)
 	} else if err != nil {
 		log.Println("ERROR: " + err.Error())
@@ -105,7 +119,7 @@ func (server *Server) handleBrowseCollection(writer http.ResponseWriter, request
 			return err
 		}
 
-		data.Collection.Collection = item.(*library.Collection)
+		data.Collection.Collection = server.Library.ExpandCollection(item.(*library.Collection))
 	} else {
 		collection, err := server.Library.GetCollectionTemplate(strings.TrimPrefix(request.URL.Path,
 			urlBrowsePath+"sources/"))
@@ -206,3 +220,296 @@ func (server *Server) handleBrowseSearch(writer http.ResponseWriter, request *ht
 
 	return tmpl.Execute(writer, data)
 }
+
+func (server *Server) handleBrowseSnapshot(writer http.ResponseWriter, request *http.Request,
+	tmpl *template.Template) error {
+
+	type snapshotGraph struct {
+		Index int
+		Name  string
+	}
+
+	var data struct {
+		URLPrefix string
+		Snapshot  *SnapshotResponse
+		Graphs    []*snapshotGraph
+	}
+
+	// Set template data
+	data.URLPrefix = server.Config.URLPrefix
+
+	item, err := server.Snapshots.Get(strings.TrimPrefix(request.URL.Path, urlBrowsePath+"snapshots/"))
+	if err != nil {
+		return err
+	}
+
+	data.Snapshot = getSnapshotResponse(item, false)
+
+	for index, plots := range item.Plots {
+		var plotResp struct {
+			Name string `json:"name"`
+		}
+
+		if err := json.Unmarshal(plots, &plotResp); err != nil {
+			return err
+		}
+
+		data.Graphs = append(data.Graphs, &snapshotGraph{Index: index, Name: plotResp.Name})
+	}
+
+	// Execute template
+	tmpl, err = tmpl.ParseFiles(
+		path.Join(server.Config.BaseDir, "html", "layout.html"),
+		path.Join(server.Config.BaseDir, "html", "common", "element.html"),
+		path.Join(server.Config.BaseDir, "html", "common", "graph.html"),
+		path.Join(server.Config.BaseDir, "html", "browse", "layout.html"),
+		path.Join(server.Config.BaseDir, "html", "browse", "snapshot.html"),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(writer, data)
+}
+
+func (server *Server) handleBrowseKiosk(writer http.ResponseWriter, request *http.Request,
+	tmpl *template.Template) error {
+
+	type kioskGraph struct {
+		ID         string
+		Options    map[string]string
+		Attributes map[string]string
+	}
+
+	type kioskPage struct {
+		Title  string
+		Graphs []*kioskGraph
+	}
+
+	var (
+		data struct {
+			URLPrefix string
+			Name      string
+			Theme     string
+			Options   map[string]string
+			Pages     []*kioskPage
+		}
+		playlist *library.Playlist
+	)
+
+	// Set template data
+	data.URLPrefix = server.Config.URLPrefix
+
+	// Get playlist from library or from request parameters
+	playlistID := strings.TrimPrefix(request.URL.Path, urlBrowsePath+"kiosk/")
+
+	if playlistID != "" {
+		item, err := server.Library.GetItem(playlistID, library.LibraryItemPlaylist)
+		if err != nil {
+			return err
+		}
+
+		playlist = item.(*library.Playlist)
+	} else {
+		request.ParseForm()
+
+		playlist = &library.Playlist{
+			Item:        library.Item{Name: "Kiosk"},
+			Collections: request.Form["collection"],
+			Interval:    request.FormValue("interval"),
+			Refresh:     request.FormValue("refresh"),
+			Range:       request.FormValue("range"),
+			Theme:       request.FormValue("theme"),
+		}
+
+		if len(playlist.Collections) == 0 {
+			return os.ErrNotExist
+		}
+
+		if request.FormValue("page_size") != "" {
+			pageSize, err := strconv.Atoi(request.FormValue("page_size"))
+			if err != nil || pageSize < 0 {
+				return os.ErrInvalid
+			}
+
+			playlist.PageSize = pageSize
+		}
+
+		if playlist.Range != "" {
+			if _, err := utils.TimeApplyRange(time.Now(), playlist.Range); err != nil {
+				return os.ErrInvalid
+			}
+		}
+
+		if playlist.Theme != "" && playlist.Theme != "light" && playlist.Theme != "dark" {
+			return os.ErrInvalid
+		}
+	}
+
+	interval, refresh, err := playlist.GetDurations()
+	if err != nil {
+		return os.ErrInvalid
+	}
+
+	data.Name = playlist.Name
+	data.Theme = playlist.Theme
+
+	data.Options = map[string]string{
+		"interval": strconv.Itoa(int(interval.Seconds())),
+		"refresh":  strconv.Itoa(int(refresh.Seconds())),
+	}
+
+	// Split collections graphs into pages, skipping collections removed since the playlist has been saved
+	for _, collectionID := range playlist.Collections {
+		item, err := server.Library.GetItem(collectionID, library.LibraryItemCollection)
+		if os.IsNotExist(err) && playlistID != "" {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		collection := server.Library.ExpandCollection(item.(*library.Collection))
+
+		pageSize := playlist.PageSize
+		if pageSize == 0 || pageSize > len(collection.Entries) {
+			pageSize = len(collection.Entries)
+		}
+
+		for start := 0; start < len(collection.Entries); start += pageSize {
+			end := start + pageSize
+			if end > len(collection.Entries) {
+				end = len(collection.Entries)
+			}
+
+			page := &kioskPage{Title: collection.Name}
+
+			if pageSize < len(collection.Entries) {
+				page.Title += fmt.Sprintf(" (%d/%d)", start/pageSize+1,
+					(len(collection.Entries)+pageSize-1)/pageSize)
+			}
+
+			for _, entry := range collection.Entries[start:end] {
+				graph := &kioskGraph{
+					ID:         entry.ID,
+					Options:    make(map[string]string),
+					Attributes: entry.Attributes,
+				}
+
+				for key, value := range entry.Options {
+					graph.Options[key] = value
+				}
+
+				if playlist.Range != "" {
+					graph.Options["range"] = playlist.Range
+				}
+
+				graph.Options["theme"] = playlist.Theme
+				graph.Options["controls"] = "false"
+				graph.Options["zoom"] = "false"
+
+				page.Graphs = append(page.Graphs, graph)
+			}
+
+			data.Pages = append(data.Pages, page)
+		}
+	}
+
+	// Execute template
+	tmpl, err = tmpl.ParseFiles(
+		path.Join(server.Config.BaseDir, "html", "layout.html"),
+		path.Join(server.Config.BaseDir, "html", "common", "graph.html"),
+		path.Join(server.Config.BaseDir, "html", "browse", "kiosk.html"),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(writer, data)
+}
+
+func (server *Server) handleBrowseTags(writer http.ResponseWriter, request *http.Request,
+	tmpl *template.Template) error {
+
+	var data struct {
+		URLPrefix   string
+		Tag         string
+		Tags        TagListResponse
+		Collections []*library.Collection
+		Graphs      []*library.Graph
+	}
+
+	// Set template data
+	data.URLPrefix = server.Config.URLPrefix
+	data.Tag = strings.TrimPrefix(request.URL.Path, urlBrowsePath+"tags/")
+
+	if data.Tag == "" {
+		for tag, count := range server.Library.GetTags(library.LibraryItemGraph, library.LibraryItemCollection) {
+			data.Tags = append(data.Tags, &TagResponse{Name: tag, Count: count})
+		}
+
+		sort.Sort(data.Tags)
+	} else {
+		tags := []string{data.Tag}
+
+		for _, collection := range server.Library.Collections {
+			if collection.HasTags(tags) {
+				data.Collections = append(data.Collections, collection)
+			}
+		}
+
+		for _, graph := range server.Library.Graphs {
+			if !graph.Volatile && !graph.Template && graph.HasTags(tags) {
+				data.Graphs = append(data.Graphs, graph)
+			}
+		}
+
+		if len(data.Collections) == 0 && len(data.Graphs) == 0 {
+			return os.ErrNotExist
+		}
+
+		sort.Sort(collectionsByName(data.Collections))
+		sort.Sort(graphsByName(data.Graphs))
+	}
+
+	// Execute template
+	tmpl, err := tmpl.ParseFiles(
+		path.Join(server.Config.BaseDir, "html", "layout.html"),
+		path.Join(server.Config.BaseDir, "html", "common", "element.html"),
+		path.Join(server.Config.BaseDir, "html", "common", "graph.html"),
+		path.Join(server.Config.BaseDir, "html", "browse", "layout.html"),
+		path.Join(server.Config.BaseDir, "html", "browse", "tags.html"),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(writer, data)
+}
+
+type collectionsByName []*library.Collection
+
+func (s collectionsByName) Len() int {
+	return len(s)
+}
+
+func (s collectionsByName) Less(i, j int) bool {
+	return s[i].Name < s[j].Name
+}
+
+func (s collectionsByName) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+type graphsByName []*library.Graph
+
+func (s graphsByName) Len() int {
+	return len(s)
+}
+
+func (s graphsByName) Less(i, j int) bool {
+	return s[i].Name < s[j].Name
+}
+
+func (s graphsByName) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
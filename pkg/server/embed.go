@@ -0,0 +1,234 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/utils"
+)
+
+func (server *Server) handleEmbed(writer http.ResponseWriter, request *http.Request) {
+	if request.URL.Path == urlEmbedPath+"plots" {
+		server.handleEmbedPlots(writer, request)
+		return
+	} else if request.Method != "GET" && request.Method != "HEAD" {
+		server.handleResponse(writer, nil, http.StatusMethodNotAllowed)
+		return
+	} else if !strings.HasPrefix(request.URL.Path, urlEmbedPath+"graphs/") {
+		server.handleError(writer, http.StatusNotFound)
+		return
+	} else if !server.checkEmbedToken(request.FormValue("token")) {
+		server.handleError(writer, http.StatusUnauthorized)
+		return
+	}
+
+	tmpl := template.New("layout.html").Funcs(template.FuncMap{
+		"asset": server.templateAsset,
+		"eq":    templateEqual,
+		"ne":    templateNotEqual,
+		"dump":  templateDumpMap,
+	})
+
+	setHTTPCacheHeaders(writer)
+
+	err := server.handleEmbedGraph(writer, request, tmpl)
+	if err == os.ErrInvalid {
+		server.handleError(writer, http.StatusBadRequest)
+	} else if os.IsNotExist(err) {
+		server.handleError(writer, http.StatusNotFound)
+	} else if err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleError(writer, http.StatusInternalServerError)
+	}
+}
+
+func (server *Server) handleEmbedGraph(writer http.ResponseWriter, request *http.Request,
+	tmpl *template.Template) error {
+
+	var (
+		data struct {
+			URLPrefix  string
+			Graph      *library.Graph
+			Theme      string
+			Options    map[string]string
+			Attributes map[string]string
+		}
+		graph *library.Graph
+		err   error
+	)
+
+	// Set template data
+	data.URLPrefix = server.Config.URLPrefix
+	data.Theme = request.FormValue("theme")
+
+	data.Options = map[string]string{
+		"embed":    "true",
+		"token":    request.FormValue("token"),
+		"range":    request.FormValue("range"),
+		"refresh":  request.FormValue("refresh"),
+		"theme":    data.Theme,
+		"legend":   request.FormValue("legend"),
+		"controls": "false",
+	}
+
+	// Check display options
+	if data.Theme != "" && data.Theme != "light" && data.Theme != "dark" {
+		return os.ErrInvalid
+	} else if legend := request.FormValue("legend"); legend != "" && legend != "true" && legend != "false" {
+		return os.ErrInvalid
+	}
+
+	if request.FormValue("refresh") != "" {
+		if refresh, err := strconv.Atoi(request.FormValue("refresh")); err != nil || refresh < 0 {
+			return os.ErrInvalid
+		}
+	}
+
+	if request.FormValue("range") != "" {
+		if _, err := utils.TimeApplyRange(time.Now(), request.FormValue("range")); err != nil {
+			return os.ErrInvalid
+		}
+	}
+
+	// Get graph from library, only serving graphs having embedding enabled
+	graphID := strings.TrimPrefix(request.URL.Path, urlEmbedPath+"graphs/")
+
+	if graphID != "" {
+		item, err := server.Library.GetItem(graphID, library.LibraryItemGraph)
+		if err != nil {
+			return err
+		}
+
+		graph = item.(*library.Graph)
+	} else {
+		originName := request.FormValue("origin")
+		sourceName := request.FormValue("source")
+		templateName := request.FormValue("template")
+
+		if origin, ok := server.Catalog.Origins[originName]; !ok || templateName == "" {
+			return os.ErrNotExist
+		} else if _, ok := origin.Sources[sourceName]; !ok {
+			return os.ErrNotExist
+		}
+
+		if graph, err = server.Library.GetGraphTemplate(originName, sourceName, templateName,
+			request.FormValue("filter")); err != nil {
+			log.Println("ERROR: " + err.Error())
+			return os.ErrNotExist
+		}
+
+		data.Options["origin"] = originName
+		data.Options["source"] = sourceName
+		data.Options["template"] = templateName
+		data.Options["filter"] = request.FormValue("filter")
+	}
+
+	if !graph.Embeddable {
+		return os.ErrNotExist
+	}
+
+	data.Graph = graph
+	data.Options["title"] = graph.Name
+
+	// Pass attributes along when embedding a graph template
+	for key := range request.Form {
+		if !strings.HasPrefix(key, renderAttributesPrefix) {
+			continue
+		}
+
+		if data.Attributes == nil {
+			data.Attributes = make(map[string]string)
+		}
+
+		data.Attributes[strings.TrimPrefix(key, renderAttributesPrefix)] = request.Form.Get(key)
+	}
+
+	// Execute template
+	tmpl, err = tmpl.ParseFiles(
+		path.Join(server.Config.BaseDir, "html", "layout.html"),
+		path.Join(server.Config.BaseDir, "html", "common", "graph.html"),
+		path.Join(server.Config.BaseDir, "html", "embed.html"),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(writer, data)
+}
+
+func (server *Server) handleEmbedPlots(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != "POST" {
+		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+		return
+	} else if utils.RequestGetContentType(request) != "application/json" {
+		server.handleResponse(writer, serverResponse{mesgUnsupportedMediaType}, http.StatusUnsupportedMediaType)
+		return
+	} else if !server.checkEmbedToken(request.FormValue("token")) {
+		server.handleResponse(writer, serverResponse{mesgAuthenticationRequired}, http.StatusUnauthorized)
+		return
+	}
+
+	// Parse input JSON for graph data
+	body, _ := ioutil.ReadAll(request.Body)
+
+	plotReq := &PlotRequest{}
+
+	if err := json.Unmarshal(body, plotReq); err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+		return
+	}
+
+	// Only serve plots of graphs having embedding enabled
+	if !server.isGraphEmbeddable(plotReq) {
+		server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+		return
+	}
+
+	response, errResponse, status := server.getPlots(plotReq)
+	if errResponse != nil {
+		server.handleResponse(writer, errResponse, status)
+		return
+	}
+
+	server.handleResponse(writer, response, http.StatusOK)
+}
+
+func (server *Server) isGraphEmbeddable(plotReq *PlotRequest) bool {
+	if plotReq.Metric != "" {
+		return false
+	} else if plotReq.Template != "" {
+		graph, err := server.Library.GetGraphTemplate(plotReq.Origin, plotReq.Source, plotReq.Template,
+			plotReq.Filter)
+
+		return err == nil && graph.Embeddable
+	}
+
+	item, err := server.Library.GetItem(plotReq.Graph, library.LibraryItemGraph)
+
+	return err == nil && item.(*library.Graph).Embeddable
+}
+
+func (server *Server) checkEmbedToken(token string) bool {
+	if len(server.Config.EmbedTokens) == 0 {
+		return true
+	}
+
+	for _, embedToken := range server.Config.EmbedTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(embedToken)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/facette/facette/pkg/types"
+	"github.com/facette/facette/pkg/utils"
+	"github.com/facette/facette/thirdparty/github.com/fatih/set"
+)
+
+const (
+	exportFlushRows = 1000
+)
+
+var exportContentTypes = map[string]string{
+	"csv":   "text/csv",
+	"tsv":   "text/tab-separated-values",
+	"jsonl": "application/x-ndjson",
+}
+
+type exportWriter interface {
+	writeHeader(names []string)
+	writeRow(key string, values []types.PlotValue, info bool)
+	flush()
+}
+
+// csvExportWriter represents an export writer producing delimiter-separated values.
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (export *csvExportWriter) writeHeader(names []string) {
+	export.writer.Write(append([]string{"time"}, names...))
+}
+
+func (export *csvExportWriter) writeRow(key string, values []types.PlotValue, info bool) {
+	record := []string{key}
+
+	for _, value := range values {
+		if math.IsNaN(float64(value)) {
+			record = append(record, "")
+		} else {
+			record = append(record, strconv.FormatFloat(float64(value), 'f', -1, 64))
+		}
+	}
+
+	export.writer.Write(record)
+}
+
+func (export *csvExportWriter) flush() {
+	export.writer.Flush()
+}
+
+// jsonlExportWriter represents an export writer producing JSON objects separated by newlines.
+type jsonlExportWriter struct {
+	encoder *json.Encoder
+	names   []string
+}
+
+type jsonlExportLine struct {
+	Time   string                     `json:"time,omitempty"`
+	Info   string                     `json:"info,omitempty"`
+	Values map[string]types.PlotValue `json:"values"`
+}
+
+func (export *jsonlExportWriter) writeHeader(names []string) {
+	export.names = names
+}
+
+func (export *jsonlExportWriter) writeRow(key string, values []types.PlotValue, info bool) {
+	line := jsonlExportLine{Values: make(map[string]types.PlotValue)}
+
+	if info {
+		line.Info = key
+	} else {
+		line.Time = key
+	}
+
+	for i, value := range values {
+		line.Values[export.names[i]] = value
+	}
+
+	export.encoder.Encode(line)
+}
+
+func (export *jsonlExportWriter) flush() {
+}
+
+func newExportWriter(output io.Writer, format string) exportWriter {
+	if format == "jsonl" {
+		return &jsonlExportWriter{encoder: json.NewEncoder(output)}
+	}
+
+	writer := csv.NewWriter(output)
+	if format == "tsv" {
+		writer.Comma = '\t'
+	}
+
+	return &csvExportWriter{writer: writer}
+}
+
+// getExportFormat returns the export format requested either using the `format' query parameter or the `Accept'
+// header, an empty string standing for the default JSON response.
+func getExportFormat(request *http.Request) (string, bool) {
+	if format := request.URL.Query().Get("format"); format != "" {
+		if format == "json" {
+			return "", true
+		}
+
+		_, ok := exportContentTypes[format]
+		return format, ok
+	}
+
+	for _, mediaType := range utils.RequestGetAccept(request) {
+		if mediaType == "application/json" {
+			break
+		}
+
+		for format, contentType := range exportContentTypes {
+			if mediaType == contentType {
+				return format, true
+			}
+		}
+	}
+
+	return "", true
+}
+
+func (server *Server) handleExport(writer http.ResponseWriter, request *http.Request, response *PlotResponse,
+	format string) {
+	var count int
+
+	series := make([]*SerieResponse, 0)
+	names := make([]string, 0)
+
+	for _, stack := range response.Stacks {
+		for _, serie := range stack.Series {
+			series = append(series, serie)
+			names = append(names, serie.Name)
+
+			if len(serie.Plots) > count {
+				count = len(serie.Plots)
+			}
+		}
+	}
+
+	fileName := response.ID
+	if fileName == "" {
+		fileName = "plots"
+	}
+
+	writer.Header().Set("Content-Type", exportContentTypes[format]+"; charset=utf-8")
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.%s\"", fileName, format))
+	writer.WriteHeader(http.StatusOK)
+
+	if request.Method == "HEAD" {
+		return
+	}
+
+	output := bufio.NewWriter(writer)
+	flusher, canFlush := writer.(http.Flusher)
+
+	export := newExportWriter(output, format)
+	export.writeHeader(names)
+
+	startTime, _ := time.Parse(time.RFC3339, response.Start)
+
+	for index := 0; index < count; index++ {
+		values := make([]types.PlotValue, len(series))
+
+		for i, serie := range series {
+			if index < len(serie.Plots) {
+				values[i] = serie.Plots[index]
+			} else {
+				values[i] = types.PlotValue(math.NaN())
+			}
+		}
+
+		export.writeRow(startTime.Add(time.Duration(float64(index)*response.Step*float64(time.Second))).
+			Format(time.RFC3339), values, false)
+
+		// Flush output regularly to stream long ranges
+		if (index+1)%exportFlushRows == 0 {
+			export.flush()
+			output.Flush()
+
+			if canFlush {
+				flusher.Flush()
+			}
+		}
+	}
+
+	// Append series information summary rows if requested
+	if request.URL.Query().Get("info") == "1" {
+		keySet := set.New()
+
+		for _, serie := range series {
+			for key := range serie.Info {
+				keySet.Add(key)
+			}
+		}
+
+		keys := set.StringSlice(keySet)
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			values := make([]types.PlotValue, len(series))
+
+			for i, serie := range series {
+				if value, ok := serie.Info[key]; ok {
+					values[i] = value
+				} else {
+					values[i] = types.PlotValue(math.NaN())
+				}
+			}
+
+			export.writeRow(key, values, true)
+		}
+	}
+
+	export.flush()
+	output.Flush()
+}
@@ -198,5 +198,9 @@ func (server *Server) getStats(writer http.ResponseWriter, request *http.Request
 		Graphs:      len(server.Library.Graphs),
 		Collections: len(server.Library.Collections),
 		Groups:      len(server.Library.Groups),
+		Alerts:      len(server.Library.GetAlerts()),
+		Playlists:   len(server.Library.Playlists),
+
+		Cache: server.Cache.Stats(),
 	}
 }
@@ -36,6 +36,11 @@ func (server *Server) parseError(writer http.ResponseWriter, request *http.Reque
 	return nil, http.StatusOK
 }
 
+func isForceRequest(request *http.Request) bool {
+	force, err := strconv.ParseBool(request.FormValue("force"))
+	return err == nil && force
+}
+
 func (server *Server) parseListRequest(writer http.ResponseWriter, request *http.Request,
 	offset, limit *int) (*serverResponse, int) {
 
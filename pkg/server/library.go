@@ -3,24 +3,125 @@ package server
 import (
 	"net/http"
 	"strings"
+
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/utils"
 )
 
 func (server *Server) handleLibrary(writer http.ResponseWriter, request *http.Request) {
 	setHTTPCacheHeaders(writer)
 
-	if strings.HasPrefix(request.URL.Path, urlLibraryPath+"sourcegroups/") {
+	if strings.HasSuffix(request.URL.Path, "/usage") {
+		server.handleLibraryUsage(writer, request)
+	} else if strings.HasPrefix(request.URL.Path, urlLibraryPath+"sourcegroups/") {
 		server.handleGroup(writer, request)
 	} else if strings.HasPrefix(request.URL.Path, urlLibraryPath+"metricgroups/") {
 		server.handleGroup(writer, request)
+	} else if request.URL.Path == urlLibraryPath+"tags" {
+		server.handleLibraryTags(writer, request)
 	} else if request.URL.Path == urlLibraryPath+"expand" {
 		server.handleGroupExpand(writer, request)
+	} else if request.URL.Path == urlLibraryPath+"export" {
+		server.handleLibraryExport(writer, request)
+	} else if request.URL.Path == urlLibraryPath+"import" {
+		server.handleLibraryImport(writer, request)
+	} else if request.URL.Path == urlLibraryPath+"graphs/instantiate" {
+		server.handleGraphInstantiate(writer, request)
 	} else if request.URL.Path == urlLibraryPath+"graphs/plots" {
 		server.handleGraphPlots(writer, request)
+	} else if request.URL.Path == urlLibraryPath+"graphs/stream" {
+		server.handleGraphStream(writer, request)
 	} else if strings.HasPrefix(request.URL.Path, urlLibraryPath+"graphs/") {
 		server.handleGraph(writer, request)
 	} else if strings.HasPrefix(request.URL.Path, urlLibraryPath+"collections/") {
 		server.handleCollection(writer, request)
+	} else if strings.HasPrefix(request.URL.Path, urlLibraryPath+"alerts/") {
+		server.handleAlertRule(writer, request)
+	} else if strings.HasPrefix(request.URL.Path, urlLibraryPath+"playlists/") {
+		server.handlePlaylist(writer, request)
 	} else {
 		server.handleResponse(writer, nil, http.StatusNotFound)
 	}
 }
+
+func (server *Server) handleLibraryTags(writer http.ResponseWriter, request *http.Request) {
+	var offset, limit int
+
+	if response, status := server.parseListRequest(writer, request, &offset, &limit); status != http.StatusOK {
+		server.handleResponse(writer, response, status)
+		return
+	}
+
+	itemTypes := make([]int, 0)
+
+	for _, typeName := range request.Form["type"] {
+		itemType, ok := getLibraryItemType(typeName)
+		if !ok {
+			server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+			return
+		}
+
+		itemTypes = append(itemTypes, itemType)
+	}
+
+	// Fill tags list
+	items := make(TagListResponse, 0)
+
+	for tag, count := range server.Library.GetTags(itemTypes...) {
+		if request.FormValue("filter") != "" && !utils.FilterMatch(request.FormValue("filter"), tag) {
+			continue
+		}
+
+		items = append(items, &TagResponse{Name: tag, Count: count})
+	}
+
+	response := &listResponse{
+		list:   items,
+		offset: offset,
+		limit:  limit,
+	}
+
+	server.applyResponseLimit(writer, request, response)
+
+	server.handleResponse(writer, response.list, http.StatusOK)
+}
+
+func (server *Server) handleLibraryUsage(writer http.ResponseWriter, request *http.Request) {
+	if response, status := server.parseShowRequest(writer, request); status != http.StatusOK {
+		server.handleResponse(writer, response, status)
+		return
+	}
+
+	chunks := strings.Split(strings.TrimPrefix(request.URL.Path, urlLibraryPath), "/")
+	if len(chunks) != 3 {
+		server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+		return
+	}
+
+	itemType, ok := getLibraryItemType(chunks[0])
+	if !ok || !server.Library.ItemExists(chunks[1], itemType) {
+		server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+		return
+	}
+
+	server.handleResponse(writer, server.Library.GetItemUsage(chunks[1], itemType), http.StatusOK)
+}
+
+func getLibraryItemType(name string) (int, bool) {
+	switch name {
+	case "sourcegroups":
+		return library.LibraryItemSourceGroup, true
+	case "metricgroups":
+		return library.LibraryItemMetricGroup, true
+	case "graphs":
+		return library.LibraryItemGraph, true
+	case "collections":
+		return library.LibraryItemCollection, true
+	case "alerts":
+		return library.LibraryItemAlert, true
+	case "playlists":
+		return library.LibraryItemPlaylist, true
+	}
+
+	return 0, false
+}
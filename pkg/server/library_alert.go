@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/utils"
+)
+
+func (server *Server) handleAlertRule(writer http.ResponseWriter, request *http.Request) {
+	alertID := strings.TrimPrefix(request.URL.Path, urlLibraryPath+"alerts/")
+
+	switch request.Method {
+	case "DELETE":
+		if alertID == "" {
+			server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+			return
+		} else if !server.handleAuth(writer, request) {
+			server.handleResponse(writer, serverResponse{mesgAuthenticationRequired}, http.StatusUnauthorized)
+			return
+		} else if !isForceRequest(request) && server.Library.IsItemUsed(alertID, library.LibraryItemAlert) {
+			server.handleResponse(writer, serverResponse{mesgResourceInUse}, http.StatusConflict)
+			return
+		}
+
+		err := server.Library.DeleteItem(alertID, library.LibraryItemAlert)
+		if os.IsNotExist(err) {
+			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+			return
+		}
+
+		server.handleResponse(writer, nil, http.StatusOK)
+
+	case "GET", "HEAD":
+		if alertID == "" {
+			server.handleAlertRuleList(writer, request)
+			return
+		}
+
+		item, err := server.Library.GetItem(alertID, library.LibraryItemAlert)
+		if os.IsNotExist(err) {
+			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+			return
+		}
+
+		server.handleResponse(writer, item, http.StatusOK)
+
+	case "POST", "PUT":
+		var alert *library.Alert
+
+		if response, status := server.parseStoreRequest(writer, request, alertID); status != http.StatusOK {
+			server.handleResponse(writer, response, status)
+			return
+		}
+
+		if request.Method == "POST" && request.FormValue("inherit") != "" {
+			// Get alert from library
+			item, err := server.Library.GetItem(request.FormValue("inherit"), library.LibraryItemAlert)
+			if os.IsNotExist(err) {
+				server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+				return
+			} else if err != nil {
+				log.Println("ERROR: " + err.Error())
+				server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+				return
+			}
+
+			alert = &library.Alert{}
+			*alert = *item.(*library.Alert)
+
+			alert.ID = ""
+		} else {
+			// Create a new alert instance
+			alert = &library.Alert{Item: library.Item{ID: alertID}}
+		}
+
+		alert.Modified = time.Now()
+
+		// Parse input JSON for alert data
+		body, _ := ioutil.ReadAll(request.Body)
+
+		if err := json.Unmarshal(body, alert); err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+			return
+		}
+
+		// Store alert data
+		err := server.Library.StoreItem(alert, library.LibraryItemAlert)
+		if response, status := server.parseError(writer, request, err); status != http.StatusOK {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, response, status)
+			return
+		}
+
+		if request.Method == "POST" {
+			writer.Header().Add("Location", strings.TrimRight(request.URL.Path, "/")+"/"+alert.ID)
+			server.handleResponse(writer, nil, http.StatusCreated)
+		} else {
+			server.handleResponse(writer, nil, http.StatusOK)
+		}
+
+	default:
+		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+	}
+}
+
+func (server *Server) handleAlertRuleList(writer http.ResponseWriter, request *http.Request) {
+	var offset, limit int
+
+	if response, status := server.parseListRequest(writer, request, &offset, &limit); status != http.StatusOK {
+		server.handleResponse(writer, response, status)
+		return
+	}
+
+	// Fill alerts list
+	items := make(ItemListResponse, 0)
+
+	for _, alert := range server.Library.GetAlerts() {
+		if request.FormValue("filter") != "" && !utils.FilterMatch(request.FormValue("filter"), alert.Name) {
+			continue
+		}
+
+		if !alert.HasTags(request.Form["tag"]) {
+			continue
+		}
+
+		items = append(items, &ItemResponse{
+			ID:          alert.ID,
+			Name:        alert.Name,
+			Description: alert.Description,
+			Tags:        alert.Tags,
+			Modified:    alert.Modified.Format(time.RFC3339),
+		})
+	}
+
+	response := &listResponse{
+		list:   items,
+		offset: offset,
+		limit:  limit,
+	}
+
+	server.applyResponseLimit(writer, request, response)
+
+	server.handleResponse(writer, response.list, http.StatusOK)
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/utils"
+)
+
+func (server *Server) handleLibraryExport(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != "GET" && request.Method != "HEAD" {
+		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	bundle, err := server.Library.ExportCollection(request.FormValue("collection"))
+	if os.IsNotExist(err) {
+		server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+		return
+	}
+
+	fileName := "facette-" + request.FormValue("collection")
+
+	switch request.FormValue("format") {
+	case "", "json":
+		writer.Header().Set("Content-Disposition", "attachment; filename=\""+fileName+".json\"")
+		server.handleResponse(writer, bundle, http.StatusOK)
+
+	case "tar":
+		buffer := bytes.NewBuffer(nil)
+
+		if err := bundle.WriteTar(buffer); err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/x-tar")
+		writer.Header().Set("Content-Disposition", "attachment; filename=\""+fileName+".tar\"")
+		writer.WriteHeader(http.StatusOK)
+		writer.Write(buffer.Bytes())
+
+	default:
+		server.handleResponse(writer, serverResponse{mesgFormatInvalid}, http.StatusBadRequest)
+	}
+}
+
+func (server *Server) handleLibraryImport(writer http.ResponseWriter, request *http.Request) {
+	var (
+		bundle *library.Bundle
+		err    error
+	)
+
+	if request.Method != "POST" {
+		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+		return
+	} else if !server.handleAuth(writer, request) {
+		server.handleResponse(writer, serverResponse{mesgAuthenticationRequired}, http.StatusUnauthorized)
+		return
+	}
+
+	strategy, err := library.ParseImportStrategy(request.FormValue("strategy"))
+	if err != nil {
+		server.handleResponse(writer, serverResponse{mesgImportStrategyInvalid}, http.StatusBadRequest)
+		return
+	}
+
+	// Parse input bundle
+	switch utils.RequestGetContentType(request) {
+	case "application/json":
+		body, _ := ioutil.ReadAll(request.Body)
+
+		bundle = &library.Bundle{}
+		err = json.Unmarshal(body, bundle)
+
+	case "application/x-tar":
+		bundle, err = library.ReadBundleTar(request.Body)
+
+	default:
+		server.handleResponse(writer, serverResponse{mesgUnsupportedMediaType}, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	if err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+		return
+	}
+
+	report, err := server.Library.ImportBundle(bundle, strategy, request.FormValue("preserve_ids") != "")
+	if response, status := server.parseError(writer, request, err); status != http.StatusOK {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, importErrorResponse{*response, report}, status)
+		return
+	}
+
+	server.handleResponse(writer, report, http.StatusOK)
+}
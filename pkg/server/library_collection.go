@@ -31,6 +31,9 @@ func (server *Server) handleCollection(writer http.ResponseWriter, request *http
 		} else if !server.handleAuth(writer, request) {
 			server.handleResponse(writer, serverResponse{mesgAuthenticationRequired}, http.StatusUnauthorized)
 			return
+		} else if !isForceRequest(request) && server.Library.IsItemUsed(collectionID, library.LibraryItemCollection) {
+			server.handleResponse(writer, serverResponse{mesgResourceInUse}, http.StatusConflict)
+			return
 		}
 
 		err := server.Library.DeleteItem(collectionID, library.LibraryItemCollection)
@@ -61,6 +64,10 @@ func (server *Server) handleCollection(writer http.ResponseWriter, request *http
 			return
 		}
 
+		if request.FormValue("expand") != "" {
+			item = server.Library.ExpandCollection(item.(*library.Collection))
+		}
+
 		server.handleResponse(writer, item, http.StatusOK)
 
 	case "POST", "PUT":
@@ -196,6 +203,10 @@ func (server *Server) handleCollectionList(writer http.ResponseWriter, request *
 			continue
 		}
 
+		if !collection.HasTags(request.Form["tag"]) {
+			continue
+		}
+
 		// Skip excluded items
 		if excludeSet.Has(collection.ID) {
 			continue
@@ -205,6 +216,7 @@ func (server *Server) handleCollectionList(writer http.ResponseWriter, request *
 			ID:          collection.ID,
 			Name:        collection.Name,
 			Description: collection.Description,
+			Tags:        collection.Tags,
 			Modified:    collection.Modified.Format(time.RFC3339),
 		}, HasChildren: len(collection.Children) > 0}
 
@@ -13,6 +13,8 @@ import (
 	"github.com/facette/facette/pkg/config"
 	"github.com/facette/facette/pkg/connector"
 	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/plot"
+	"github.com/facette/facette/pkg/types"
 	"github.com/facette/facette/pkg/utils"
 	"github.com/facette/facette/thirdparty/github.com/fatih/set"
 )
@@ -28,6 +30,9 @@ func (server *Server) handleGraph(writer http.ResponseWriter, request *http.Requ
 		} else if !server.handleAuth(writer, request) {
 			server.handleResponse(writer, serverResponse{mesgAuthenticationRequired}, http.StatusUnauthorized)
 			return
+		} else if !isForceRequest(request) && server.Library.IsItemUsed(graphID, library.LibraryItemGraph) {
+			server.handleResponse(writer, serverResponse{mesgResourceInUse}, http.StatusConflict)
+			return
 		}
 
 		err := server.Library.DeleteItem(graphID, library.LibraryItemGraph)
@@ -148,7 +153,7 @@ func (server *Server) handleGraphList(writer http.ResponseWriter, request *http.
 			return
 		}
 
-		collection := item.(*library.Collection)
+		collection := server.Library.ExpandCollection(item.(*library.Collection))
 
 		for _, graph := range collection.Entries {
 			graphSet.Add(graph.ID)
@@ -167,10 +172,15 @@ func (server *Server) handleGraphList(writer http.ResponseWriter, request *http.
 			continue
 		}
 
+		if !graph.HasTags(request.Form["tag"]) {
+			continue
+		}
+
 		items = append(items, &ItemResponse{
 			ID:          graph.ID,
 			Name:        graph.Name,
 			Description: graph.Description,
+			Tags:        graph.Tags,
 			Modified:    graph.Modified.Format(time.RFC3339),
 		})
 	}
@@ -186,14 +196,74 @@ func (server *Server) handleGraphList(writer http.ResponseWriter, request *http.
 	server.handleResponse(writer, response.list, http.StatusOK)
 }
 
-func (server *Server) handleGraphPlots(writer http.ResponseWriter, request *http.Request) {
-	var (
-		err                error
-		graph              *library.Graph
-		item               interface{}
-		startTime, endTime time.Time
-	)
+func (server *Server) handleGraphInstantiate(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != "POST" {
+		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+		return
+	} else if response, status := server.parseStoreRequest(writer, request, ""); status != http.StatusOK {
+		server.handleResponse(writer, response, status)
+		return
+	}
 
+	// Parse input JSON for instantiation data
+	body, _ := ioutil.ReadAll(request.Body)
+
+	instReq := &InstantiateRequest{}
+
+	if err := json.Unmarshal(body, instReq); err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+		return
+	}
+
+	// Get graph template from library
+	item, err := server.Library.GetItem(instReq.Template, library.LibraryItemGraph)
+	if os.IsNotExist(err) {
+		server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+		return
+	}
+
+	graph, err := server.Library.InstantiateGraph(item.(*library.Graph), instReq.Attributes)
+	if err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, serverResponse{mesgTemplateAttributes}, http.StatusBadRequest)
+		return
+	}
+
+	graph.ID = ""
+	graph.Modified = time.Now()
+
+	if instReq.Name != "" {
+		graph.Name = instReq.Name
+	}
+
+	if instReq.Description != "" {
+		graph.Description = instReq.Description
+	}
+
+	// Store graph data
+	if request.FormValue("volatile") != "" {
+		graph.Volatile = true
+	} else {
+		graph.Volatile = false
+	}
+
+	err = server.Library.StoreItem(graph, library.LibraryItemGraph)
+	if response, status := server.parseError(writer, request, err); status != http.StatusOK {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, response, status)
+		return
+	}
+
+	writer.Header().Add("Location", urlLibraryPath+"graphs/"+graph.ID)
+	server.handleResponse(writer, nil, http.StatusCreated)
+}
+
+func (server *Server) handleGraphPlots(writer http.ResponseWriter, request *http.Request) {
 	if request.Method != "POST" && request.Method != "HEAD" {
 		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
 		return
@@ -202,6 +272,12 @@ func (server *Server) handleGraphPlots(writer http.ResponseWriter, request *http
 		return
 	}
 
+	format, ok := getExportFormat(request)
+	if !ok {
+		server.handleResponse(writer, serverResponse{mesgFormatInvalid}, http.StatusBadRequest)
+		return
+	}
+
 	// Parse input JSON for graph data
 	body, _ := ioutil.ReadAll(request.Body)
 
@@ -213,44 +289,52 @@ func (server *Server) handleGraphPlots(writer http.ResponseWriter, request *http
 		return
 	}
 
+	response, errResponse, status := server.getPlots(plotReq)
+	if errResponse != nil {
+		server.handleResponse(writer, errResponse, status)
+		return
+	}
+
+	if format != "" {
+		server.handleExport(writer, request, response, format)
+		return
+	}
+
+	server.handleResponse(writer, response, http.StatusOK)
+}
+
+func (server *Server) getPlots(plotReq *PlotRequest) (*PlotResponse, *serverResponse, int) {
+	var (
+		err                error
+		graph              *library.Graph
+		item               interface{}
+		startTime, endTime time.Time
+	)
+
 	if plotReq.Origin != "" && plotReq.Template != "" {
 		plotReq.Graph = plotReq.Origin + "\x30" + plotReq.Template
 	} else if plotReq.Origin != "" && plotReq.Metric != "" {
 		plotReq.Graph = plotReq.Origin + "\x30" + plotReq.Metric
 	}
 
-	if plotReq.Time == "" {
-		endTime = time.Now()
-	} else if strings.HasPrefix(strings.Trim(plotReq.Range, " "), "-") {
-		if endTime, err = time.Parse(time.RFC3339, plotReq.Time); err != nil {
-			log.Println("ERROR: " + err.Error())
-			server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
-			return
-		}
-	} else {
-		if startTime, err = time.Parse(time.RFC3339, plotReq.Time); err != nil {
-			log.Println("ERROR: " + err.Error())
-			server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
-			return
-		}
-	}
-
-	if startTime.IsZero() {
-		if startTime, err = utils.TimeApplyRange(endTime, plotReq.Range); err != nil {
-			log.Println("ERROR: " + err.Error())
-			server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
-			return
-		}
-	} else if endTime, err = utils.TimeApplyRange(startTime, plotReq.Range); err != nil {
+	if startTime, endTime, err = getPlotTimeRange(plotReq); err != nil {
 		log.Println("ERROR: " + err.Error())
-		server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
-		return
+		return nil, &serverResponse{mesgTimeRangeInvalid + ": " + err.Error()}, http.StatusBadRequest
 	}
 
 	if plotReq.Sample == 0 {
 		plotReq.Sample = config.DefaultPlotSample
 	}
 
+	if len(plotReq.Percentiles) == 0 {
+		plotReq.Percentiles = plot.DefaultPercentiles
+	}
+
+	if err = plot.ValidateDownsample(plotReq.Downsample); err != nil {
+		log.Println("ERROR: " + err.Error())
+		return nil, &serverResponse{mesgResourceInvalid}, http.StatusBadRequest
+	}
+
 	// Get graph from library
 	if plotReq.Template != "" {
 		graph, err = server.Library.GetGraphTemplate(
@@ -271,48 +355,65 @@ func (server *Server) handleGraphPlots(writer http.ResponseWriter, request *http
 
 	if err != nil {
 		if os.IsNotExist(err) {
-			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
-		} else {
-			log.Println("ERROR: " + err.Error())
-			server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+			return nil, &serverResponse{mesgResourceNotFound}, http.StatusNotFound
 		}
 
-		return
+		log.Println("ERROR: " + err.Error())
+		return nil, &serverResponse{mesgUnhandledError}, http.StatusInternalServerError
 	}
 
-	step := endTime.Sub(startTime) / time.Duration(plotReq.Sample)
+	// Apply attributes to graph template
+	if graph.Template {
+		if graph, err = server.Library.InstantiateGraph(graph, plotReq.Attributes); err != nil {
+			log.Println("ERROR: " + err.Error())
+			return nil, &serverResponse{mesgTemplateAttributes}, http.StatusBadRequest
+		}
+	}
 
-	// Get plots data
-	groupOptions := make(map[string]map[string]interface{})
+	step := endTime.Sub(startTime) / time.Duration(plotReq.Sample)
 
-	data := make([]map[string]*connector.PlotResult, 0)
+	// Parse comparison time shifts
+	shiftStarts := make([]time.Time, len(plotReq.Shifts))
 
-	for _, stackItem := range graph.Stacks {
-		for _, groupItem := range stackItem.Groups {
-			query, originConnector, err := server.preparePlotQuery(plotReq, groupItem)
-			if err != nil {
-				log.Println("ERROR: " + err.Error())
-				server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
-				return
-			}
+	for index, shift := range plotReq.Shifts {
+		if shiftStarts[index], err = utils.TimeApplyRange(startTime, shift); err != nil {
+			log.Println("ERROR: " + err.Error())
+			return nil, &serverResponse{mesgResourceInvalid}, http.StatusBadRequest
+		}
+	}
 
-			groupOptions[groupItem.Name] = groupItem.Options
+	// Get plots data
+	data, fills, err := server.getGraphPlots(plotReq, graph, startTime, endTime, step)
+	if err != nil {
+		log.Println("ERROR: " + err.Error())
+		return nil, &serverResponse{mesgUnhandledError}, http.StatusInternalServerError
+	}
 
-			plotResult, err := originConnector.GetPlots(query, startTime, endTime, step, plotReq.Percentiles)
-			if err != nil {
-				log.Println("ERROR: " + err.Error())
-				server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
-				return
-			}
+	// Get comparison plots data over shifted time ranges
+	shiftData := make([][]map[string]*connector.PlotResult, len(plotReq.Shifts))
 
-			data = append(data, plotResult)
+	for index, shiftStart := range shiftStarts {
+		if shiftData[index], _, err = server.getGraphPlots(plotReq, graph, shiftStart,
+			shiftStart.Add(endTime.Sub(startTime)), step); err != nil {
+			log.Println("ERROR: " + err.Error())
+			return nil, &serverResponse{mesgUnhandledError}, http.StatusInternalServerError
 		}
 	}
 
+	// Get previous periods plots data for seasonal anomaly analyses
+	periodData, err := server.getAnomalyPeriods(plotReq, graph, startTime, endTime, step)
+	if err != nil {
+		log.Println("ERROR: " + err.Error())
+		return nil, &serverResponse{mesgUnhandledError}, http.StatusInternalServerError
+	}
+
+	// Extend time range up to the farthest forecasting horizon
+	forecastEnd := getForecastEnd(graph, startTime, endTime)
+
 	response := &PlotResponse{
 		ID:          graph.ID,
 		Start:       startTime.Format(time.RFC3339),
-		End:         endTime.Format(time.RFC3339),
+		End:         forecastEnd.Format(time.RFC3339),
 		Step:        step.Seconds(),
 		Name:        graph.Name,
 		Description: graph.Description,
@@ -322,47 +423,102 @@ func (server *Server) handleGraphPlots(writer http.ResponseWriter, request *http
 	}
 
 	if len(data) == 0 {
-		server.handleResponse(writer, serverResponse{mesgEmptyData}, http.StatusOK)
-		return
+		return nil, &serverResponse{mesgEmptyData}, http.StatusOK
 	}
 
 	plotMax := 0
+	index := 0
 
 	for _, stackItem := range graph.Stacks {
 		stack := &StackResponse{Name: stackItem.Name}
 
 		for _, groupItem := range stackItem.Groups {
-			var plotResult map[string]*connector.PlotResult
+			for serieName, serieResult := range data[index] {
+				var (
+					forecastSeries, anomalySeries []*SerieResponse
+					anomalies                     []*AnomalyResponse
+				)
 
-			plotResult, data = data[0], data[1:]
+				if forecast := getSerieForecast(groupItem, serieName); forecast != nil {
+					if forecastSeries, err = getForecastSeries(serieName, serieResult, forecast, startTime, endTime,
+						forecastEnd, plotReq.Percentiles); err != nil {
+						log.Println("ERROR: " + err.Error())
+						return nil, &serverResponse{mesgUnhandledError}, http.StatusInternalServerError
+					}
+				}
+
+				if anomaly := getSerieAnomaly(groupItem, serieName); anomaly != nil {
+					if anomalySeries, anomalies, err = getAnomalySeries(serieName, serieResult, anomaly, index,
+						periodData, startTime, endTime, forecastEnd, plotReq.Percentiles); err != nil {
+						log.Println("ERROR: " + err.Error())
+						return nil, &serverResponse{mesgUnhandledError}, http.StatusInternalServerError
+					}
+				}
+
+				// Ensure series don't exceed the requested sample
+				serieResult.Plots = plot.Downsample(padPlots(serieResult.Plots, paddedLength(serieResult.Plots,
+					startTime, endTime, forecastEnd)), plotReq.Sample, plotReq.Downsample)
 
-			for serieName, serieResult := range plotResult {
 				if len(serieResult.Plots) > plotMax {
 					plotMax = len(serieResult.Plots)
 				}
 
 				stack.Series = append(stack.Series, &SerieResponse{
-					Name:    serieName,
-					Plots:   serieResult.Plots,
-					Info:    serieResult.Info,
-					Options: groupOptions[groupItem.Name],
+					Name:      serieName,
+					Plots:     serieResult.Plots,
+					Info:      serieResult.Info,
+					Options:   groupItem.Options,
+					Fill:      fills[serieName],
+					Anomalies: anomalies,
 				})
+
+				// Append forecast and anomaly bands series following their reference ones
+				for _, derivedSerie := range append(forecastSeries, anomalySeries...) {
+					derivedSerie.Plots = plot.Downsample(derivedSerie.Plots, plotReq.Sample, plotReq.Downsample)
+					derivedSerie.Options = groupItem.Options
+
+					stack.Series = append(stack.Series, derivedSerie)
+				}
 			}
+
+			// Append comparison series following their reference ones
+			for shiftIndex, shift := range plotReq.Shifts {
+				for serieName, serieResult := range shiftData[shiftIndex][index] {
+					shiftStart := shiftStarts[shiftIndex]
+					shiftEnd := shiftStart.Add(endTime.Sub(startTime))
+
+					stack.Series = append(stack.Series, &SerieResponse{
+						Name: fmt.Sprintf("%s (%s)", serieName, strings.TrimSpace(shift)),
+						Plots: plot.Downsample(padPlots(serieResult.Plots, paddedLength(serieResult.Plots, shiftStart,
+							shiftEnd, shiftEnd.Add(forecastEnd.Sub(endTime)))), plotReq.Sample, plotReq.Downsample),
+						Info:       serieResult.Info,
+						Options:    groupItem.Options,
+						Fill:       fills[serieName],
+						Comparison: true,
+						Shift:      strings.TrimSpace(shift),
+					})
+				}
+			}
+
+			index++
 		}
 
 		response.Stacks = append(response.Stacks, stack)
 	}
 
 	if plotMax > 0 {
-		response.Step = (endTime.Sub(startTime) / time.Duration(plotMax)).Seconds()
+		response.Step = (forecastEnd.Sub(startTime) / time.Duration(plotMax)).Seconds()
 	}
 
-	server.handleResponse(writer, response, http.StatusOK)
-}
+	// Attach annotations matching graph sources over the requested time range if requested
+	if plotReq.Annotations {
+		response.Annotations = server.getPlotAnnotations(plotReq, graph, startTime, endTime)
+	}
 
-func (server *Server) preparePlotQuery(plotReq *PlotRequest, groupItem *library.OperGroup) (*connector.GroupQuery,
-	connector.Connector, error) {
+	return response, nil, http.StatusOK
+}
 
+func (server *Server) preparePlotQuery(plotReq *PlotRequest, groupItem *library.OperGroup) (*plotQuery, error) {
 	var originConnector connector.Connector
 
 	query := &connector.GroupQuery{
@@ -371,16 +527,18 @@ func (server *Server) preparePlotQuery(plotReq *PlotRequest, groupItem *library.
 		Scale: groupItem.Scale,
 	}
 
+	series := make(map[string]*library.Serie)
+
 	originConnector = nil
 
 	for _, serieItem := range groupItem.Series {
 		// Check for connectors errors or conflicts
 		if _, ok := server.Catalog.Origins[serieItem.Origin]; !ok {
-			return nil, nil, fmt.Errorf("unknown `%s' serie origin", serieItem.Origin)
+			return nil, fmt.Errorf("unknown `%s' serie origin", serieItem.Origin)
 		} else if originConnector == nil {
 			originConnector = server.Catalog.Origins[serieItem.Origin].Connector
 		} else if originConnector != server.Catalog.Origins[serieItem.Origin].Connector {
-			return nil, nil, fmt.Errorf("connectors differ between series")
+			return nil, fmt.Errorf("connectors differ between series")
 		}
 
 		serieSources := make([]string, 0)
@@ -407,8 +565,8 @@ func (server *Server) preparePlotQuery(plotReq *PlotRequest, groupItem *library.
 					)
 
 					if metric == nil {
-						log.Printf("unknown `%s' metric for source `%s' (origin: %s)", serieChunk, serieSource,
-							serieItem.Origin)
+						return nil, fmt.Errorf("unknown `%s' metric for source `%s' (origin: %s)", serieChunk,
+							serieSource, serieItem.Origin)
 					}
 
 					query.Series = append(query.Series, &connector.SerieQuery{
@@ -420,6 +578,8 @@ func (server *Server) preparePlotQuery(plotReq *PlotRequest, groupItem *library.
 						Scale: serieItem.Scale,
 					})
 
+					series[fmt.Sprintf("%s-%d", serieItem.Name, index)] = serieItem
+
 					index += 1
 				}
 			} else {
@@ -430,8 +590,8 @@ func (server *Server) preparePlotQuery(plotReq *PlotRequest, groupItem *library.
 				)
 
 				if metric == nil {
-					log.Printf("unknown `%s' metric for source `%s' (origin: %s)", serieItem.Metric, serieSource,
-						serieItem.Origin)
+					return nil, fmt.Errorf("unknown `%s' metric for source `%s' (origin: %s)", serieItem.Metric,
+						serieSource, serieItem.Origin)
 				}
 
 				serie := &connector.SerieQuery{
@@ -449,6 +609,7 @@ func (server *Server) preparePlotQuery(plotReq *PlotRequest, groupItem *library.
 				}
 
 				query.Series = append(query.Series, serie)
+				series[serie.Name] = serieItem
 
 				index += 1
 			}
@@ -456,8 +617,62 @@ func (server *Server) preparePlotQuery(plotReq *PlotRequest, groupItem *library.
 	}
 
 	if len(query.Series) == 0 {
-		return nil, nil, fmt.Errorf("no serie defined")
+		return nil, fmt.Errorf("no serie defined")
+	}
+
+	return &plotQuery{
+		query:     query,
+		connector: originConnector,
+		origin:    groupItem.Series[0].Origin,
+		series:    series,
+	}, nil
+}
+
+func (server *Server) getCachedPlots(originName string, originConnector connector.Connector,
+	query *connector.GroupQuery, startTime, endTime time.Time, step time.Duration,
+	percentiles []float64) (map[string]*connector.PlotResult, error) {
+
+	var ttl time.Duration
+
+	if originConfig, ok := server.Config.Origins[originName]; ok {
+		ttl = time.Duration(originConfig.CacheTTL) * time.Second
+	}
+
+	if ttl <= 0 {
+		return originConnector.GetPlots(query, startTime, endTime, step, percentiles)
+	}
+
+	// Round time boundaries to steps so that close requests share the same entry
+	if step > 0 {
+		startTime, endTime = startTime.Truncate(step), endTime.Truncate(step)
+	}
+
+	key, err := json.Marshal([]interface{}{originName, query, startTime.Unix(), endTime.Unix(), int64(step),
+		percentiles})
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := server.Cache.Get(string(key), ttl, func() (interface{}, error) {
+		return originConnector.GetPlots(query, startTime, endTime, step, percentiles)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Return a copy of the cached results to prevent their alteration
+	result := make(map[string]*connector.PlotResult)
+
+	for serieName, serieResult := range value.(map[string]*connector.PlotResult) {
+		result[serieName] = &connector.PlotResult{
+			Plots: append([]types.PlotValue(nil), serieResult.Plots...),
+			Info:  make(map[string]types.PlotValue),
+		}
+
+		for key, value := range serieResult.Info {
+			result[serieName].Info[key] = value
+		}
 	}
 
-	return query, originConnector, nil
+	return result, nil
 }
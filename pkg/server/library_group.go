@@ -36,6 +36,9 @@ func (server *Server) handleGroup(writer http.ResponseWriter, request *http.Requ
 		} else if !server.handleAuth(writer, request) {
 			server.handleResponse(writer, serverResponse{mesgAuthenticationRequired}, http.StatusUnauthorized)
 			return
+		} else if !isForceRequest(request) && server.Library.IsItemUsed(groupID, groupType) {
+			server.handleResponse(writer, serverResponse{mesgResourceInUse}, http.StatusConflict)
+			return
 		}
 
 		err := server.Library.DeleteItem(groupID, groupType)
@@ -151,10 +154,15 @@ func (server *Server) handleGroupList(writer http.ResponseWriter, request *http.
 			continue
 		}
 
+		if !group.HasTags(request.Form["tag"]) {
+			continue
+		}
+
 		items = append(items, &ItemResponse{
 			ID:          group.ID,
 			Name:        group.Name,
 			Description: group.Description,
+			Tags:        group.Tags,
 			Modified:    group.Modified.Format(time.RFC3339),
 		})
 	}
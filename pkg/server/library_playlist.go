@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/utils"
+)
+
+func (server *Server) handlePlaylist(writer http.ResponseWriter, request *http.Request) {
+	playlistID := strings.TrimPrefix(request.URL.Path, urlLibraryPath+"playlists/")
+
+	switch request.Method {
+	case "DELETE":
+		if playlistID == "" {
+			server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+			return
+		} else if !server.handleAuth(writer, request) {
+			server.handleResponse(writer, serverResponse{mesgAuthenticationRequired}, http.StatusUnauthorized)
+			return
+		} else if !isForceRequest(request) && server.Library.IsItemUsed(playlistID, library.LibraryItemPlaylist) {
+			server.handleResponse(writer, serverResponse{mesgResourceInUse}, http.StatusConflict)
+			return
+		}
+
+		err := server.Library.DeleteItem(playlistID, library.LibraryItemPlaylist)
+		if os.IsNotExist(err) {
+			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+			return
+		}
+
+		server.handleResponse(writer, nil, http.StatusOK)
+
+	case "GET", "HEAD":
+		if playlistID == "" {
+			server.handlePlaylistList(writer, request)
+			return
+		}
+
+		item, err := server.Library.GetItem(playlistID, library.LibraryItemPlaylist)
+		if os.IsNotExist(err) {
+			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+			return
+		}
+
+		server.handleResponse(writer, item, http.StatusOK)
+
+	case "POST", "PUT":
+		var playlist *library.Playlist
+
+		if response, status := server.parseStoreRequest(writer, request, playlistID); status != http.StatusOK {
+			server.handleResponse(writer, response, status)
+			return
+		}
+
+		if request.Method == "POST" && request.FormValue("inherit") != "" {
+			// Get playlist from library
+			item, err := server.Library.GetItem(request.FormValue("inherit"), library.LibraryItemPlaylist)
+			if os.IsNotExist(err) {
+				server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+				return
+			} else if err != nil {
+				log.Println("ERROR: " + err.Error())
+				server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+				return
+			}
+
+			playlist = &library.Playlist{}
+			*playlist = *item.(*library.Playlist)
+
+			playlist.ID = ""
+		} else {
+			// Create a new playlist instance
+			playlist = &library.Playlist{Item: library.Item{ID: playlistID}}
+		}
+
+		playlist.Modified = time.Now()
+
+		// Parse input JSON for playlist data
+		body, _ := ioutil.ReadAll(request.Body)
+
+		if err := json.Unmarshal(body, playlist); err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+			return
+		}
+
+		// Store playlist data
+		err := server.Library.StoreItem(playlist, library.LibraryItemPlaylist)
+		if response, status := server.parseError(writer, request, err); status != http.StatusOK {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, response, status)
+			return
+		}
+
+		if request.Method == "POST" {
+			writer.Header().Add("Location", strings.TrimRight(request.URL.Path, "/")+"/"+playlist.ID)
+			server.handleResponse(writer, nil, http.StatusCreated)
+		} else {
+			server.handleResponse(writer, nil, http.StatusOK)
+		}
+
+	default:
+		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+	}
+}
+
+func (server *Server) handlePlaylistList(writer http.ResponseWriter, request *http.Request) {
+	var offset, limit int
+
+	if response, status := server.parseListRequest(writer, request, &offset, &limit); status != http.StatusOK {
+		server.handleResponse(writer, response, status)
+		return
+	}
+
+	// Fill playlists list
+	items := make(ItemListResponse, 0)
+
+	for _, playlist := range server.Library.Playlists {
+		if request.FormValue("filter") != "" && !utils.FilterMatch(request.FormValue("filter"), playlist.Name) {
+			continue
+		}
+
+		if !playlist.HasTags(request.Form["tag"]) {
+			continue
+		}
+
+		items = append(items, &ItemResponse{
+			ID:          playlist.ID,
+			Name:        playlist.Name,
+			Description: playlist.Description,
+			Tags:        playlist.Tags,
+			Modified:    playlist.Modified.Format(time.RFC3339),
+		})
+	}
+
+	response := &listResponse{
+		list:   items,
+		offset: offset,
+		limit:  limit,
+	}
+
+	server.applyResponseLimit(writer, request, response)
+
+	server.handleResponse(writer, response.list, http.StatusOK)
+}
@@ -3,14 +3,19 @@ package server
 const (
 	mesgAuthenticationRequired string = "An authentication is required"
 	mesgEmptyData              string = "No data"
+	mesgFormatInvalid          string = "Requested format is not supported"
 	mesgFormLimitInvalid       string = "Request limit must be an integer"
 	mesgFormOffsetInvalid      string = "Request offset must be an integer"
 	mesgFormOffsetOutOfRange   string = "Request offset is out of range"
+	mesgImportStrategyInvalid  string = "Import strategy is invalid"
 	mesgMethodNotAllowed       string = "Request method is not allowed"
 	mesgResourceConflict       string = "A resource conflict has occured"
+	mesgResourceInUse          string = "Resource is referenced by other resources"
 	mesgResourceInvalid        string = "Resource is invalid"
 	mesgResourceNotFound       string = "Unable to find requested resource"
 	mesgServiceLoading         string = "Service is loading"
+	mesgTemplateAttributes     string = "Template attributes are missing"
+	mesgTimeRangeInvalid       string = "Requested time range is invalid"
 	mesgUnhandledError         string = "An unhandled error has occured"
 	mesgUnsupportedMediaType   string = "Provided media type is not supported"
 )
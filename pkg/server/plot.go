@@ -0,0 +1,482 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/facette/facette/pkg/connector"
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/plot"
+	"github.com/facette/facette/pkg/types"
+	"github.com/facette/facette/pkg/utils"
+)
+
+type plotQuery struct {
+	query     *connector.GroupQuery
+	connector connector.Connector
+	origin    string
+	series    map[string]*library.Serie
+}
+
+func getPlotTimeRange(plotReq *PlotRequest) (time.Time, time.Time, error) {
+	var (
+		err                error
+		startTime, endTime time.Time
+	)
+
+	location := time.Local
+
+	if plotReq.Timezone != "" {
+		if location, err = time.LoadLocation(plotReq.Timezone); err != nil {
+			return startTime, endTime, fmt.Errorf("unknown `%s' time zone", plotReq.Timezone)
+		}
+	}
+
+	now := time.Now().In(location)
+
+	if plotReq.Start != "" || plotReq.End != "" {
+		// Handle absolute time range, falling back on relative range if no start is given
+		endTime = now
+
+		if plotReq.End != "" {
+			if endTime, err = utils.TimeParse(now, plotReq.End); err != nil {
+				return startTime, endTime, fmt.Errorf("invalid end time, %s", err)
+			}
+		}
+
+		if plotReq.Start != "" {
+			if startTime, err = utils.TimeParse(now, plotReq.Start); err != nil {
+				return startTime, endTime, fmt.Errorf("invalid start time, %s", err)
+			}
+		} else if plotReq.Range == "" {
+			return startTime, endTime, fmt.Errorf("missing start time")
+		} else if startTime, err = utils.TimeApplyRange(endTime, plotReq.Range); err != nil {
+			return startTime, endTime, fmt.Errorf("invalid `%s' range", plotReq.Range)
+		}
+	} else {
+		// Handle relative time range
+		var refTime time.Time
+
+		if plotReq.Time == "" {
+			refTime = now
+		} else if refTime, err = utils.TimeParse(now, plotReq.Time); err != nil {
+			return startTime, endTime, fmt.Errorf("invalid reference time, %s", err)
+		}
+
+		if strings.HasPrefix(strings.TrimSpace(plotReq.Range), "-") || plotReq.Time == "" {
+			endTime = refTime
+			startTime, err = utils.TimeApplyRange(endTime, plotReq.Range)
+		} else {
+			startTime = refTime
+			endTime, err = utils.TimeApplyRange(startTime, plotReq.Range)
+		}
+
+		if err != nil {
+			return startTime, endTime, fmt.Errorf("invalid `%s' range", plotReq.Range)
+		}
+	}
+
+	if !startTime.Before(endTime) {
+		return startTime, endTime, fmt.Errorf("start time must be before end time")
+	}
+
+	return startTime.In(location), endTime.In(location), nil
+}
+
+func (server *Server) getGraphPlots(plotReq *PlotRequest, graph *library.Graph, startTime, endTime time.Time,
+	step time.Duration) ([]map[string]*connector.PlotResult, map[string]string, error) {
+
+	data := make([]map[string]*connector.PlotResult, 0)
+	fills := make(map[string]string)
+	expressions := make(map[int]*library.OperGroup)
+
+	for _, stackItem := range graph.Stacks {
+		for _, groupItem := range stackItem.Groups {
+			// Defer expression groups evaluation until all the other series are fetched
+			if groupItem.Type == connector.OperGroupTypeExpression {
+				expressions[len(data)] = groupItem
+				data = append(data, nil)
+				continue
+			}
+
+			plotResult, err := server.getGroupPlots(plotReq, groupItem, startTime, endTime, step, fills)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			data = append(data, plotResult)
+		}
+	}
+
+	if len(expressions) > 0 {
+		if err := evaluateExpressions(data, expressions, startTime, endTime, plotReq.Percentiles,
+			fills); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return data, fills, nil
+}
+
+func (server *Server) getGroupPlots(plotReq *PlotRequest, groupItem *library.OperGroup, startTime, endTime time.Time,
+	step time.Duration, fills map[string]string) (map[string]*connector.PlotResult, error) {
+
+	query, err := server.preparePlotQuery(plotReq, groupItem)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query series separately if they need to be processed prior to the group operation
+	groupType := query.query.Type
+	serieProcessing := false
+
+	for _, serieItem := range groupItem.Series {
+		if len(serieItem.Transforms) > 0 || serieItem.Fill != "" {
+			serieProcessing = true
+			break
+		}
+	}
+
+	if serieProcessing && len(query.query.Series) > 1 {
+		query.query.Type = connector.OperGroupTypeNone
+	}
+
+	result, err := server.getCachedPlots(query.origin, query.connector, query.query, startTime, endTime, step,
+		plotReq.Percentiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if serieProcessing {
+		for serieName, serieResult := range result {
+			if serieItem, ok := query.series[serieName]; ok && (serieItem.Fill != "" ||
+				len(serieItem.Transforms) > 0) {
+				processPlots(serieResult, serieItem.Fill, serieItem.Transforms, startTime, endTime)
+
+				fills[serieName] = serieItem.Fill
+			}
+		}
+	}
+
+	// Apply group operation on processed series
+	if query.query.Type != groupType {
+		series := make([][]types.PlotValue, 0)
+
+		for _, serieQuery := range query.query.Series {
+			if serieResult, ok := result[serieQuery.Name]; ok {
+				series = append(series, serieResult.Plots)
+			}
+		}
+
+		groupResult := &connector.PlotResult{}
+
+		if groupType == connector.OperGroupTypeAvg {
+			groupResult.Plots = plot.Average(series...)
+		} else {
+			groupResult.Plots = plot.Sum(series...)
+		}
+
+		result = map[string]*connector.PlotResult{groupItem.Name: groupResult}
+	}
+
+	if groupItem.Fill != "" || len(groupItem.Transforms) > 0 {
+		for serieName, serieResult := range result {
+			processPlots(serieResult, groupItem.Fill, groupItem.Transforms, startTime, endTime)
+
+			if groupItem.Fill != "" {
+				fills[serieName] = groupItem.Fill
+			}
+		}
+	}
+
+	// Compute information statistics server-side for consistency across connectors
+	for _, serieResult := range result {
+		summarizePlots(serieResult, startTime, endTime, plotReq.Percentiles)
+	}
+
+	return result, nil
+}
+
+func evaluateExpressions(data []map[string]*connector.PlotResult, groups map[int]*library.OperGroup, startTime,
+	endTime time.Time, percentiles []float64, fills map[string]string) error {
+
+	series := make(map[string][]types.PlotValue)
+
+	for _, plotResult := range data {
+		for serieName, serieResult := range plotResult {
+			series[serieName] = serieResult.Plots
+		}
+	}
+
+	// Evaluate expressions in definition order, allowing them to reference previous ones
+	for index := range data {
+		groupItem, ok := groups[index]
+		if !ok {
+			continue
+		}
+
+		expression, err := plot.ParseExpression(groupItem.Expression)
+		if err != nil {
+			return fmt.Errorf("in `%s' group expression, %s", groupItem.Name, err)
+		}
+
+		plots, err := expression.Evaluate(series)
+		if err != nil {
+			return fmt.Errorf("in `%s' group expression, %s", groupItem.Name, err)
+		}
+
+		plotResult := &connector.PlotResult{Plots: plots}
+		processPlots(plotResult, groupItem.Fill, groupItem.Transforms, startTime, endTime)
+		summarizePlots(plotResult, startTime, endTime, percentiles)
+
+		data[index] = map[string]*connector.PlotResult{groupItem.Name: plotResult}
+
+		series[groupItem.Name] = plotResult.Plots
+		fills[groupItem.Name] = groupItem.Fill
+	}
+
+	return nil
+}
+
+func processPlots(plotResult *connector.PlotResult, fill string, transforms []*plot.Transform, startTime,
+	endTime time.Time) {
+
+	if fill != "" {
+		plotResult.Plots = plot.Fill(plotResult.Plots, fill)
+	}
+
+	if len(transforms) > 0 {
+		plotResult.Plots = plot.ApplyTransforms(plotResult.Plots, plotsStep(plotResult.Plots, startTime, endTime),
+			transforms)
+	}
+}
+
+func summarizePlots(plotResult *connector.PlotResult, startTime, endTime time.Time, percentiles []float64) {
+	plotResult.Info = plot.Summarize(plotResult.Plots, startTime, plotsStep(plotResult.Plots, startTime, endTime),
+		percentiles)
+}
+
+func plotsStep(plots []types.PlotValue, startTime, endTime time.Time) time.Duration {
+	if len(plots) == 0 {
+		return 0
+	}
+
+	return endTime.Sub(startTime) / time.Duration(len(plots))
+}
+
+func getForecastEnd(graph *library.Graph, startTime, endTime time.Time) time.Time {
+	forecastEnd := endTime
+	maxEnd := endTime.Add(endTime.Sub(startTime) * plot.MaxForecastRatio)
+
+	for _, stackItem := range graph.Stacks {
+		for _, groupItem := range stackItem.Groups {
+			forecasts := []*plot.Forecast{groupItem.Forecast}
+
+			for _, serieItem := range groupItem.Series {
+				forecasts = append(forecasts, serieItem.Forecast)
+			}
+
+			for _, forecast := range forecasts {
+				if forecast == nil {
+					continue
+				}
+
+				if horizon, err := forecast.HorizonDuration(endTime); err == nil && endTime.Add(horizon).After(forecastEnd) {
+					forecastEnd = endTime.Add(horizon)
+				}
+			}
+		}
+	}
+
+	if forecastEnd.After(maxEnd) {
+		forecastEnd = maxEnd
+	}
+
+	return forecastEnd
+}
+
+func getSerieForecast(groupItem *library.OperGroup, serieName string) *plot.Forecast {
+	if groupItem.Forecast != nil {
+		return groupItem.Forecast
+	} else if serieItem := getGroupSerie(groupItem, serieName); serieItem != nil {
+		return serieItem.Forecast
+	}
+
+	return nil
+}
+
+func getSerieAnomaly(groupItem *library.OperGroup, serieName string) *plot.Anomaly {
+	if groupItem.Anomaly != nil {
+		return groupItem.Anomaly
+	} else if serieItem := getGroupSerie(groupItem, serieName); serieItem != nil {
+		return serieItem.Anomaly
+	}
+
+	return nil
+}
+
+func getGroupSerie(groupItem *library.OperGroup, serieName string) *library.Serie {
+	for _, serieItem := range groupItem.Series {
+		if serieItem.Name == serieName {
+			return serieItem
+		}
+	}
+
+	return nil
+}
+
+func getForecastSeries(serieName string, serieResult *connector.PlotResult, forecast *plot.Forecast, startTime,
+	endTime, forecastEnd time.Time, percentiles []float64) ([]*SerieResponse, error) {
+
+	step := plotsStep(serieResult.Plots, startTime, endTime)
+	if step <= 0 {
+		return nil, nil
+	}
+
+	prediction, err := forecast.Predict(serieResult.Plots, endTime, step)
+	if err != nil {
+		return nil, err
+	}
+
+	if forecast.Threshold != nil {
+		serieResult.Info["time_to_threshold"] = types.PlotValue(prediction.TimeToThreshold)
+	}
+
+	length := paddedLength(serieResult.Plots, startTime, endTime, forecastEnd)
+	result := make([]*SerieResponse, 0)
+
+	for _, entry := range []struct {
+		name  string
+		band  string
+		plots []types.PlotValue
+	}{
+		{serieName + " (forecast)", "", prediction.Plots},
+		{serieName + " (upper)", "upper", prediction.Upper},
+		{serieName + " (lower)", "lower", prediction.Lower},
+	} {
+		result = append(result, &SerieResponse{
+			Name:     entry.name,
+			Plots:    padPlots(entry.plots, length),
+			Info:     plot.Summarize(entry.plots, startTime, step, percentiles),
+			Forecast: true,
+			Band:     entry.band,
+		})
+	}
+
+	return result, nil
+}
+
+func (server *Server) getAnomalyPeriods(plotReq *PlotRequest, graph *library.Graph, startTime, endTime time.Time,
+	step time.Duration) (map[int64][]map[string]*connector.PlotResult, error) {
+
+	result := make(map[int64][]map[string]*connector.PlotResult)
+
+	for _, stackItem := range graph.Stacks {
+		for _, groupItem := range stackItem.Groups {
+			anomalies := []*plot.Anomaly{groupItem.Anomaly}
+
+			for _, serieItem := range groupItem.Series {
+				anomalies = append(anomalies, serieItem.Anomaly)
+			}
+
+			for _, anomaly := range anomalies {
+				if anomaly == nil {
+					continue
+				}
+
+				periodStarts, err := anomaly.PeriodStarts(startTime)
+				if err != nil {
+					return nil, err
+				}
+
+				// Fetch each previous period only once whatever the number of analyses relying on it
+				for _, periodStart := range periodStarts {
+					if _, ok := result[periodStart.Unix()]; ok {
+						continue
+					}
+
+					if result[periodStart.Unix()], _, err = server.getGraphPlots(plotReq, graph, periodStart,
+						periodStart.Add(endTime.Sub(startTime)), step); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func getAnomalySeries(serieName string, serieResult *connector.PlotResult, anomaly *plot.Anomaly, index int,
+	periodData map[int64][]map[string]*connector.PlotResult, startTime, endTime,
+	forecastEnd time.Time, percentiles []float64) ([]*SerieResponse, []*AnomalyResponse, error) {
+
+	step := plotsStep(serieResult.Plots, startTime, endTime)
+	if step <= 0 {
+		return nil, nil, nil
+	}
+
+	periodStarts, err := anomaly.PeriodStarts(startTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	periods := make([][]types.PlotValue, 0)
+
+	for _, periodStart := range periodStarts {
+		if data, ok := periodData[periodStart.Unix()]; ok && index < len(data) && data[index][serieName] != nil {
+			periods = append(periods, data[index][serieName].Plots)
+		}
+	}
+
+	detection := anomaly.Detect(serieResult.Plots, periods)
+
+	intervals := make([]*AnomalyResponse, 0)
+
+	for _, interval := range detection.Intervals {
+		intervals = append(intervals, &AnomalyResponse{
+			Start: startTime.Add(step * time.Duration(interval[0])).Format(time.RFC3339),
+			End:   startTime.Add(step * time.Duration(interval[1])).Format(time.RFC3339),
+		})
+	}
+
+	length := paddedLength(serieResult.Plots, startTime, endTime, forecastEnd)
+	series := make([]*SerieResponse, 0)
+
+	for _, entry := range []struct {
+		band  string
+		plots []types.PlotValue
+	}{
+		{"upper", detection.Upper},
+		{"lower", detection.Lower},
+	} {
+		series = append(series, &SerieResponse{
+			Name:    fmt.Sprintf("%s (anomaly %s)", serieName, entry.band),
+			Plots:   padPlots(entry.plots, length),
+			Info:    plot.Summarize(entry.plots, startTime, step, percentiles),
+			Anomaly: true,
+			Band:    entry.band,
+		})
+	}
+
+	return series, intervals, nil
+}
+
+func padPlots(plots []types.PlotValue, length int) []types.PlotValue {
+	for len(plots) < length {
+		plots = append(plots, types.PlotValue(math.NaN()))
+	}
+
+	return plots
+}
+
+func paddedLength(plots []types.PlotValue, startTime, endTime, forecastEnd time.Time) int {
+	// Extend plots values up to the forecasting horizon end
+	if step := plotsStep(plots, startTime, endTime); step > 0 {
+		return len(plots) + int(forecastEnd.Sub(endTime)/step)
+	}
+
+	return len(plots)
+}
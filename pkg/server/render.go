@@ -0,0 +1,211 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facette/facette/pkg/plot"
+	"github.com/facette/facette/pkg/render"
+)
+
+const (
+	renderDefaultRange     = "-1h"
+	renderAttributesPrefix = "attr."
+)
+
+func (server *Server) handleRender(writer http.ResponseWriter, request *http.Request) {
+	var err error
+
+	if request.Method != "GET" && request.Method != "HEAD" {
+		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+		return
+	} else if !strings.HasPrefix(request.URL.Path, urlRenderPath+"graphs/") {
+		server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+		return
+	}
+
+	fileName := strings.TrimPrefix(request.URL.Path, urlRenderPath+"graphs/")
+	format := path.Ext(fileName)
+
+	if format != ".png" && format != ".svg" {
+		server.handleResponse(writer, serverResponse{mesgFormatInvalid}, http.StatusBadRequest)
+		return
+	}
+
+	// Parse plot request from query parameters
+	plotReq, err := parsePlotForm(request)
+	if err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+		return
+	}
+
+	plotReq.Graph = strings.TrimSuffix(fileName, format)
+
+	graph := &render.Graph{}
+
+	for key, value := range map[string]*int{"width": &graph.Width, "height": &graph.Height} {
+		if request.FormValue(key) == "" {
+			continue
+		} else if *value, err = strconv.Atoi(request.FormValue(key)); err != nil {
+			server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	plotResp, errResponse, status := server.getPlots(plotReq)
+	if errResponse != nil && status == http.StatusOK {
+		// Render a placeholder image displaying the message when no data is available
+		graph.Title = request.FormValue("title")
+		graph.Message = errResponse.Message
+		graph.Start, graph.End, _ = getPlotTimeRange(plotReq)
+
+		server.handleRenderImage(writer, graph, format)
+		return
+	} else if errResponse != nil {
+		server.handleResponse(writer, errResponse, status)
+		return
+	}
+
+	graph.Title = plotResp.Name
+	graph.Type = plotResp.Type
+	graph.StackMode = plotResp.StackMode
+	graph.Start, _ = time.Parse(time.RFC3339, plotResp.Start)
+	graph.End, _ = time.Parse(time.RFC3339, plotResp.End)
+
+	if request.FormValue("title") != "" {
+		graph.Title = request.FormValue("title")
+	}
+
+	for _, stackItem := range plotResp.Stacks {
+		stack := &render.Stack{Name: stackItem.Name}
+		derivedStack := &render.Stack{Name: stackItem.Name + " (derived)"}
+
+		for _, serieItem := range stackItem.Series {
+			serie := &render.Serie{
+				Name:  serieItem.Name,
+				Plots: serieItem.Plots,
+				Info:  serieItem.Info,
+			}
+
+			// Join points around dropped missing values
+			if serieItem.Fill == plot.FillDrop {
+				serie.Plots = plot.Fill(serieItem.Plots, plot.FillLinear)
+			}
+
+			if color, ok := serieItem.Options["color"].(string); ok && !serieItem.Comparison {
+				serie.Color = color
+			}
+
+			for _, anomaly := range serieItem.Anomalies {
+				interval := &render.Interval{}
+				interval.Start, _ = time.Parse(time.RFC3339, anomaly.Start)
+				interval.End, _ = time.Parse(time.RFC3339, anomaly.End)
+
+				graph.Intervals = append(graph.Intervals, interval)
+			}
+
+			// Keep forecast and anomaly bands series apart from their reference stack
+			if serieItem.Forecast || serieItem.Anomaly {
+				derivedStack.Series = append(derivedStack.Series, serie)
+			} else {
+				stack.Series = append(stack.Series, serie)
+			}
+		}
+
+		graph.Stacks = append(graph.Stacks, stack)
+
+		if len(derivedStack.Series) > 0 {
+			graph.Stacks = append(graph.Stacks, derivedStack)
+		}
+	}
+
+	server.handleRenderImage(writer, graph, format)
+}
+
+func (server *Server) handleRenderImage(writer http.ResponseWriter, graph *render.Graph, format string) {
+	var err error
+
+	// Render graph image
+	buffer := bytes.NewBuffer(nil)
+
+	if format == ".png" {
+		err = render.RenderPNG(buffer, graph)
+	} else {
+		err = render.RenderSVG(buffer, graph)
+	}
+
+	if err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+		return
+	}
+
+	setHTTPCacheHeaders(writer)
+
+	writer.Header().Set("Content-Type", mime.TypeByExtension(format))
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(buffer.Bytes())
+}
+
+func parsePlotForm(request *http.Request) (*PlotRequest, error) {
+	var err error
+
+	plotReq := &PlotRequest{
+		Time:       request.FormValue("time"),
+		Range:      request.FormValue("range"),
+		Start:      request.FormValue("start"),
+		End:        request.FormValue("end"),
+		Timezone:   request.FormValue("timezone"),
+		Origin:     request.FormValue("origin"),
+		Source:     request.FormValue("source"),
+		Metric:     request.FormValue("metric"),
+		Template:   request.FormValue("template"),
+		Filter:     request.FormValue("filter"),
+		Downsample: request.FormValue("downsample"),
+	}
+
+	if plotReq.Range == "" && plotReq.Start == "" {
+		plotReq.Range = renderDefaultRange
+	}
+
+	if request.FormValue("sample") != "" {
+		if plotReq.Sample, err = strconv.Atoi(request.FormValue("sample")); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.FormValue("percentiles") != "" {
+		for _, chunk := range strings.Split(request.FormValue("percentiles"), ",") {
+			value, err := strconv.ParseFloat(strings.TrimSpace(chunk), 64)
+			if err != nil {
+				return nil, err
+			}
+
+			plotReq.Percentiles = append(plotReq.Percentiles, value)
+		}
+	}
+
+	plotReq.Shifts = request.Form["shift"]
+
+	for key := range request.Form {
+		if !strings.HasPrefix(key, renderAttributesPrefix) {
+			continue
+		}
+
+		if plotReq.Attributes == nil {
+			plotReq.Attributes = make(map[string]string)
+		}
+
+		plotReq.Attributes[strings.TrimPrefix(key, renderAttributesPrefix)] = request.Form.Get(key)
+	}
+
+	return plotReq, nil
+}
@@ -23,6 +23,13 @@ func (writer ResponseWriter) WriteHeader(status int) {
 	}
 }
 
+// Flush sends any buffered data to the client, if supported by the underlying response writer.
+func (writer ResponseWriter) Flush() {
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // Router represents the structure of an HTTP requests router.
 type Router struct {
 	*http.ServeMux
@@ -36,7 +43,8 @@ func (router *Router) ServeHTTP(writer http.ResponseWriter, request *http.Reques
 	}
 
 	if router.server.Loading {
-		if strings.HasPrefix(request.URL.Path, urlAdminPath) || strings.HasPrefix(request.URL.Path, urlBrowsePath) {
+		if strings.HasPrefix(request.URL.Path, urlAdminPath) || strings.HasPrefix(request.URL.Path, urlBrowsePath) ||
+			strings.HasPrefix(request.URL.Path, urlEmbedPath) {
 			router.server.handleWait(writer, request)
 			return
 		} else if request.URL.Path == urlReloadPath {
@@ -8,39 +8,59 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/facette/facette/pkg/alert"
+	"github.com/facette/facette/pkg/annotation"
 	"github.com/facette/facette/pkg/auth"
+	"github.com/facette/facette/pkg/cache"
 	"github.com/facette/facette/pkg/catalog"
 	"github.com/facette/facette/pkg/config"
 	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/notifier"
+	"github.com/facette/facette/pkg/snapshot"
 	"github.com/facette/facette/thirdparty/github.com/etix/stoppableListener"
 )
 
 const (
-	serverStopWait  int    = 10
-	urlAdminPath    string = "/admin/"
-	urlBrowsePath   string = "/browse/"
-	urlCatalogPath  string = "/catalog/"
-	urlLibraryPath  string = "/library/"
-	urlReloadPath   string = "/reload"
-	urlResourcePath string = "/resources"
-	urlStaticPath   string = "/static/"
-	urlStatsPath    string = "/stats"
+	serverStopWait     int    = 10
+	urlAdminPath       string = "/admin/"
+	urlAlertsPath      string = "/alerts/"
+	urlAnnotationsPath string = "/annotations/"
+	urlBrowsePath      string = "/browse/"
+	urlCatalogPath     string = "/catalog/"
+	urlEmbedPath       string = "/embed/"
+	urlLibraryPath     string = "/library/"
+	urlNotifyPath      string = "/notifications"
+	urlReloadPath      string = "/reload"
+	urlRenderPath      string = "/render/"
+	urlResourcePath    string = "/resources"
+	urlSnapshotsPath   string = "/snapshots/"
+	urlStaticPath      string = "/static/"
+	urlStatsPath       string = "/stats"
 )
 
 // Server is the main structure of the server handler.
 type Server struct {
-	Config      *config.Config
-	Listener    *stoppableListener.StoppableListener
-	AuthHandler auth.Handler
-	Catalog     *catalog.Catalog
-	Library     *library.Library
-	Loading     bool
-	debugLevel  int
+	Config       *config.Config
+	Listener     *stoppableListener.StoppableListener
+	AuthHandler  auth.Handler
+	Catalog      *catalog.Catalog
+	Library      *library.Library
+	Alerts       *alert.Scheduler
+	Notifier     *notifier.Notifier
+	Annotations  *annotation.Store
+	Snapshots    *snapshot.Store
+	Cache        *cache.Cache
+	Loading      bool
+	debugLevel   int
+	streams      map[string]*plotStream
+	streamsMutex sync.Mutex
 }
 
-// Reload reloads the configuration and refreshes authentication handler, catalog and library.
+// Reload reloads the configuration and refreshes authentication handler, catalog, library, notifier, annotations and
+// snapshots.
 func (server *Server) Reload() error {
 	server.Loading = true
 
@@ -55,6 +75,10 @@ func (server *Server) Reload() error {
 
 	server.Catalog.Refresh()
 	server.Library.Refresh()
+	server.Cache.Purge()
+	server.Notifier.Refresh()
+	server.Annotations.Refresh()
+	server.Snapshots.Refresh()
 
 	server.Loading = false
 
@@ -98,11 +122,46 @@ func (server *Server) Run() error {
 	}
 
 	// Create catalog and library instances
+	loadWait := &sync.WaitGroup{}
+	loadWait.Add(2)
+
 	server.Catalog = catalog.NewCatalog(server.Config, server.debugLevel)
-	go server.Catalog.Refresh()
+	go func() {
+		defer loadWait.Done()
+		server.Catalog.Refresh()
+	}()
 
 	server.Library = library.NewLibrary(server.Config, server.Catalog, server.debugLevel)
-	go server.Library.Refresh()
+	go func() {
+		defer loadWait.Done()
+		server.Library.Refresh()
+	}()
+
+	server.Cache = cache.NewCache(server.Config.CacheSize)
+
+	server.streams = make(map[string]*plotStream)
+
+	server.Annotations = annotation.NewStore(path.Join(server.Config.DataDir, "annotations"), server.debugLevel)
+	go server.Annotations.Refresh()
+
+	server.Snapshots = snapshot.NewStore(path.Join(server.Config.DataDir, "snapshots"), server.debugLevel)
+	go server.Snapshots.Refresh()
+
+	// Start alert rules evaluation and notification
+	server.Notifier = notifier.NewNotifier(server.Config, server.debugLevel)
+	if err := server.Notifier.Refresh(); err != nil {
+		return err
+	}
+
+	server.Alerts = alert.NewScheduler(server.Library, server.fetchAlertInfo, server.debugLevel)
+	server.Alerts.AddHandler(server.Notifier.Handle)
+	server.Alerts.SetSkip(func() bool { return server.Loading })
+
+	// Only evaluate alert rules once the catalog and the library have been loaded
+	go func() {
+		loadWait.Wait()
+		server.Alerts.Run()
+	}()
 
 	// Create authentication handler
 	authHandler, err := auth.NewAuth(server.Config.Auth, server.debugLevel)
@@ -121,10 +180,16 @@ func (server *Server) Run() error {
 	router.HandleFunc(urlStaticPath, server.handleStatic)
 	router.HandleFunc(urlCatalogPath, server.handleCatalog)
 	router.HandleFunc(urlLibraryPath, server.handleLibrary)
+	router.HandleFunc(urlNotifyPath, server.handleNotifications)
 	router.HandleFunc(urlAdminPath, server.handleAdmin)
+	router.HandleFunc(urlAlertsPath, server.handleAlerts)
+	router.HandleFunc(urlAnnotationsPath, server.handleAnnotations)
 	router.HandleFunc(urlBrowsePath, server.handleBrowse)
+	router.HandleFunc(urlEmbedPath, server.handleEmbed)
 	router.HandleFunc(urlReloadPath, server.handleReload)
+	router.HandleFunc(urlRenderPath, server.handleRender)
 	router.HandleFunc(urlResourcePath, server.handleResource)
+	router.HandleFunc(urlSnapshotsPath, server.handleSnapshots)
 	router.HandleFunc(urlStatsPath, server.handleStats)
 
 	router.HandleFunc("/", server.handleBrowse)
@@ -174,6 +239,10 @@ func (server *Server) Run() error {
 
 // Stop stops the server.
 func (server *Server) Stop() {
+	if server.Alerts != nil {
+		server.Alerts.Stop()
+	}
+
 	server.Listener.Stop <- true
 }
 
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facette/facette/pkg/library"
+	"github.com/facette/facette/pkg/snapshot"
+	"github.com/facette/facette/pkg/utils"
+)
+
+func (server *Server) handleSnapshots(writer http.ResponseWriter, request *http.Request) {
+	setHTTPCacheHeaders(writer)
+
+	chunks := strings.SplitN(strings.TrimPrefix(request.URL.Path, urlSnapshotsPath), "/", 3)
+	snapshotID := chunks[0]
+
+	switch request.Method {
+	case "DELETE":
+		if snapshotID == "" || len(chunks) > 1 {
+			server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+			return
+		} else if !server.handleAuth(writer, request) {
+			server.handleResponse(writer, serverResponse{mesgAuthenticationRequired}, http.StatusUnauthorized)
+			return
+		}
+
+		err := server.Snapshots.Delete(snapshotID)
+		if os.IsNotExist(err) {
+			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+			return
+		}
+
+		server.handleResponse(writer, nil, http.StatusOK)
+
+	case "GET", "HEAD":
+		if snapshotID == "" {
+			// Only list snapshots to authenticated users, their identifiers granting access to their data
+			if !server.handleAuth(writer, request) {
+				server.handleResponse(writer, serverResponse{mesgAuthenticationRequired}, http.StatusUnauthorized)
+				return
+			}
+
+			server.handleSnapshotList(writer, request)
+			return
+		}
+
+		item, err := server.Snapshots.Get(snapshotID)
+		if os.IsNotExist(err) {
+			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+			return
+		}
+
+		if len(chunks) == 1 {
+			server.handleResponse(writer, getSnapshotResponse(item, true), http.StatusOK)
+			return
+		} else if len(chunks) != 3 || chunks[1] != "plots" {
+			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+			return
+		}
+
+		// Return a single graph plots from the snapshot
+		index, err := strconv.Atoi(chunks[2])
+		if err != nil || index < 0 || index >= len(item.Plots) {
+			server.handleResponse(writer, serverResponse{mesgResourceNotFound}, http.StatusNotFound)
+			return
+		}
+
+		server.handleResponse(writer, item.Plots[index], http.StatusOK)
+
+	case "POST":
+		if response, status := server.parseStoreRequest(writer, request, snapshotID); status != http.StatusOK {
+			server.handleResponse(writer, response, status)
+			return
+		}
+
+		// Parse input JSON for snapshot request
+		body, _ := ioutil.ReadAll(request.Body)
+
+		snapReq := &SnapshotRequest{}
+
+		if err := json.Unmarshal(body, snapReq); err != nil {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+			return
+		}
+
+		item, errResponse, status := server.getSnapshot(snapReq)
+		if errResponse != nil {
+			server.handleResponse(writer, errResponse, status)
+			return
+		}
+
+		// Store snapshot data
+		err := server.Snapshots.Store(item)
+		if response, status := server.parseError(writer, request, err); status != http.StatusOK {
+			log.Println("ERROR: " + err.Error())
+			server.handleResponse(writer, response, status)
+			return
+		}
+
+		writer.Header().Add("Location", strings.TrimRight(request.URL.Path, "/")+"/"+item.ID)
+		server.handleResponse(writer, nil, http.StatusCreated)
+
+	default:
+		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+	}
+}
+
+func (server *Server) handleSnapshotList(writer http.ResponseWriter, request *http.Request) {
+	var offset, limit int
+
+	if response, status := server.parseListRequest(writer, request, &offset, &limit); status != http.StatusOK {
+		server.handleResponse(writer, response, status)
+		return
+	}
+
+	// Fill snapshots list
+	items := make(SnapshotListResponse, 0)
+
+	for _, item := range server.Snapshots.List() {
+		if request.FormValue("filter") != "" && !utils.FilterMatch(request.FormValue("filter"), item.Name) {
+			continue
+		}
+
+		items = append(items, getSnapshotResponse(item, false))
+	}
+
+	response := &listResponse{
+		list:   items,
+		offset: offset,
+		limit:  limit,
+	}
+
+	server.applyResponseLimit(writer, request, response)
+
+	server.handleResponse(writer, response.list, http.StatusOK)
+}
+
+func (server *Server) getSnapshot(snapReq *SnapshotRequest) (*snapshot.Snapshot, *serverResponse, int) {
+	item := &snapshot.Snapshot{
+		Name:    snapReq.Name,
+		Created: time.Now(),
+	}
+
+	if snapReq.Expire != "" {
+		expires, err := utils.TimeApplyRange(item.Created, snapReq.Expire)
+		if err != nil || !expires.After(item.Created) {
+			return nil, &serverResponse{mesgResourceInvalid}, http.StatusBadRequest
+		}
+
+		item.Expires = &expires
+	}
+
+	if snapReq.Range == "" && snapReq.Start == "" {
+		snapReq.Range = renderDefaultRange
+	}
+
+	// Get plots requests for the requested graph or for each of the collection graphs
+	plotReqs := make([]*PlotRequest, 0)
+
+	if snapReq.Collection != "" {
+		libraryItem, err := server.Library.GetItem(snapReq.Collection, library.LibraryItemCollection)
+		if os.IsNotExist(err) {
+			return nil, &serverResponse{mesgResourceNotFound}, http.StatusNotFound
+		} else if err != nil {
+			log.Println("ERROR: " + err.Error())
+			return nil, &serverResponse{mesgUnhandledError}, http.StatusInternalServerError
+		}
+
+		collection := server.Library.ExpandCollection(libraryItem.(*library.Collection))
+
+		for _, entry := range collection.Entries {
+			plotReq := snapReq.PlotRequest
+			plotReq.Graph = entry.ID
+			plotReq.Attributes = entry.Attributes
+
+			plotReqs = append(plotReqs, &plotReq)
+		}
+
+		item.Collection = collection.ID
+
+		if item.Name == "" {
+			item.Name = collection.Name
+		}
+	} else {
+		plotReq := snapReq.PlotRequest
+		plotReqs = append(plotReqs, &plotReq)
+	}
+
+	// Run plots queries once, skipping graphs having no data
+	for _, plotReq := range plotReqs {
+		response, errResponse, status := server.getPlots(plotReq)
+		if errResponse != nil && status == http.StatusOK {
+			continue
+		} else if errResponse != nil {
+			return nil, errResponse, status
+		}
+
+		data, err := json.Marshal(response)
+		if err != nil {
+			log.Println("ERROR: " + err.Error())
+			return nil, &serverResponse{mesgUnhandledError}, http.StatusInternalServerError
+		}
+
+		item.Plots = append(item.Plots, data)
+
+		if snapReq.Collection == "" {
+			item.Graph = response.ID
+
+			if item.Name == "" {
+				item.Name = response.Name
+			}
+		}
+	}
+
+	if len(item.Plots) == 0 {
+		return nil, &serverResponse{mesgEmptyData}, http.StatusBadRequest
+	}
+
+	return item, nil, http.StatusOK
+}
+
+func getSnapshotResponse(item *snapshot.Snapshot, plots bool) *SnapshotResponse {
+	response := &SnapshotResponse{
+		ID:         item.ID,
+		Name:       item.Name,
+		Graph:      item.Graph,
+		Collection: item.Collection,
+		Created:    item.Created.Format(time.RFC3339),
+	}
+
+	if item.Expires != nil {
+		response.Expires = item.Expires.Format(time.RFC3339)
+	}
+
+	if plots {
+		response.Plots = item.Plots
+	}
+
+	return response
+}
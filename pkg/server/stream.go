@@ -0,0 +1,377 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/facette/facette/pkg/config"
+	"github.com/facette/facette/pkg/types"
+	"github.com/facette/facette/pkg/utils"
+)
+
+const (
+	streamDefaultRefresh int = 10
+	streamBufferSize     int = 16
+)
+
+type plotStream struct {
+	key         string
+	plotReq     PlotRequest
+	duration    time.Duration
+	step        time.Duration
+	refresh     time.Duration
+	subscribers map[chan []byte]bool
+	last        *PlotResponse
+	stopChan    chan bool
+}
+
+func (server *Server) handleGraphStream(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != "GET" {
+		server.handleResponse(writer, serverResponse{mesgMethodNotAllowed}, http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		server.handleResponse(writer, serverResponse{mesgUnhandledError}, http.StatusInternalServerError)
+		return
+	}
+
+	// Parse plot request from query parameters, streams only supporting sliding time ranges
+	plotReq, err := parsePlotForm(request)
+	if err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+		return
+	} else if plotReq.Time != "" || plotReq.Start != "" || plotReq.End != "" {
+		server.handleResponse(writer, serverResponse{mesgTimeRangeInvalid}, http.StatusBadRequest)
+		return
+	}
+
+	plotReq.Graph = request.FormValue("graph")
+
+	refresh := streamDefaultRefresh
+
+	if request.FormValue("refresh") != "" {
+		if refresh, err = strconv.Atoi(request.FormValue("refresh")); err != nil || refresh < 1 {
+			server.handleResponse(writer, serverResponse{mesgResourceInvalid}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	stream, err := newPlotStream(plotReq, time.Duration(refresh)*time.Second)
+	if err != nil {
+		log.Println("ERROR: " + err.Error())
+		server.handleResponse(writer, serverResponse{mesgTimeRangeInvalid}, http.StatusBadRequest)
+		return
+	}
+
+	channel, errResponse, status := server.subscribeStream(stream)
+	if errResponse != nil {
+		server.handleResponse(writer, errResponse, status)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("X-Accel-Buffering", "no")
+
+	writer.WriteHeader(http.StatusOK)
+	writer.Write([]byte(fmt.Sprintf("retry: %d\n\n", refresh*1000)))
+	flusher.Flush()
+
+	for {
+		select {
+		case frame, ok := <-channel:
+			if !ok {
+				// Subscriber has been dropped for not consuming events fast enough
+				return
+			}
+
+			if _, err := writer.Write(frame); err != nil {
+				server.unsubscribeStream(stream.key, channel)
+				return
+			}
+
+			flusher.Flush()
+
+		case <-request.Context().Done():
+			server.unsubscribeStream(stream.key, channel)
+			return
+		}
+	}
+}
+
+func (server *Server) subscribeStream(stream *plotStream) (chan []byte, *serverResponse, int) {
+	channel := make(chan []byte, streamBufferSize)
+
+	server.streamsMutex.Lock()
+
+	if existing, ok := server.streams[stream.key]; ok {
+		existing.subscribers[channel] = true
+		channel <- getStreamFrame("plots", existing.last)
+
+		server.streamsMutex.Unlock()
+
+		return channel, nil, http.StatusOK
+	}
+
+	server.streamsMutex.Unlock()
+
+	// Perform initial query outside of lock, reporting errors as for plain plots requests
+	response, errResponse, status := server.getPlots(stream.request(time.Now()))
+	if errResponse != nil {
+		return nil, errResponse, status
+	}
+
+	server.streamsMutex.Lock()
+	defer server.streamsMutex.Unlock()
+
+	// Join stream if it has been started by another subscriber meanwhile
+	if existing, ok := server.streams[stream.key]; ok {
+		stream = existing
+	} else {
+		stream.last = response
+		server.streams[stream.key] = stream
+
+		if server.debugLevel > 0 {
+			log.Printf("DEBUG: starting `%s' graph stream", stream.plotReq.Graph)
+		}
+
+		go server.runStream(stream)
+	}
+
+	stream.subscribers[channel] = true
+	channel <- getStreamFrame("plots", stream.last)
+
+	return channel, nil, http.StatusOK
+}
+
+func (server *Server) unsubscribeStream(key string, channel chan []byte) {
+	server.streamsMutex.Lock()
+	defer server.streamsMutex.Unlock()
+
+	stream, ok := server.streams[key]
+	if !ok {
+		return
+	}
+
+	server.dropStreamSubscriber(stream, channel)
+}
+
+func (server *Server) dropStreamSubscriber(stream *plotStream, channel chan []byte) {
+	if _, ok := stream.subscribers[channel]; !ok {
+		return
+	}
+
+	delete(stream.subscribers, channel)
+	close(channel)
+
+	// Stop querying backends once the last subscriber has gone
+	if len(stream.subscribers) == 0 {
+		if server.debugLevel > 0 {
+			log.Printf("DEBUG: stopping `%s' graph stream", stream.plotReq.Graph)
+		}
+
+		close(stream.stopChan)
+		delete(server.streams, stream.key)
+	}
+}
+
+func (server *Server) runStream(stream *plotStream) {
+	ticker := time.NewTicker(stream.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stream.stopChan:
+			return
+
+		case now := <-ticker.C:
+			server.updateStream(stream, now)
+		}
+	}
+}
+
+func (server *Server) getStreamPlots(stream *plotStream, now time.Time) (response *PlotResponse,
+	errResponse *serverResponse) {
+
+	// Report failing plots queries to subscribers instead of crashing the whole process, as streams are updated
+	// outside of any HTTP request handling
+	defer func() {
+		if data := recover(); data != nil {
+			log.Printf("ERROR: unable to update `%s' graph stream: %v", stream.plotReq.Graph, data)
+			response, errResponse = nil, &serverResponse{mesgUnhandledError}
+		}
+	}()
+
+	response, errResponse, _ = server.getPlots(stream.request(now))
+
+	return response, errResponse
+}
+
+func (server *Server) updateStream(stream *plotStream, now time.Time) {
+	var frame []byte
+
+	response, errResponse := server.getStreamPlots(stream, now)
+
+	server.streamsMutex.Lock()
+	defer server.streamsMutex.Unlock()
+
+	if errResponse != nil {
+		frame = getStreamFrame("failure", errResponse)
+	} else if update := getStreamUpdate(stream.last, response); len(update.Series) > 0 {
+		frame = getStreamFrame("points", update)
+		stream.last = response
+	} else {
+		// Keep connections alive through proxies when no new point is available
+		frame = []byte(": keepalive\n\n")
+	}
+
+	for channel := range stream.subscribers {
+		select {
+		case channel <- frame:
+		default:
+			log.Printf("WARNING: dropping `%s' graph stream subscriber not consuming events", stream.plotReq.Graph)
+			server.dropStreamSubscriber(stream, channel)
+		}
+	}
+}
+
+func (stream *plotStream) request(now time.Time) *PlotRequest {
+	plotReq := stream.plotReq
+
+	// Align time range on step boundaries so that successive queries share their points timestamps
+	endTime := now.Truncate(stream.step)
+
+	plotReq.Range = ""
+	plotReq.Start = endTime.Add(-stream.duration).Format(time.RFC3339)
+	plotReq.End = endTime.Format(time.RFC3339)
+
+	return &plotReq
+}
+
+func getStreamUpdate(previous, current *PlotResponse) *StreamResponse {
+	update := &StreamResponse{
+		Start:  current.Start,
+		End:    current.End,
+		Step:   current.Step,
+		Series: make([]*StreamSerieResponse, 0),
+	}
+
+	// Get time of the last known point for each previous serie, trailing missing values being sent again
+	lastTimes := make(map[[2]string]time.Time)
+	known := make(map[[2]string]bool)
+
+	previousStart, _ := time.Parse(time.RFC3339, previous.Start)
+
+	for _, stack := range previous.Stacks {
+		for _, serie := range stack.Series {
+			known[[2]string{stack.Name, serie.Name}] = true
+
+			for i := len(serie.Plots) - 1; i >= 0; i-- {
+				if !math.IsNaN(float64(serie.Plots[i])) {
+					lastTimes[[2]string{stack.Name, serie.Name}] = getPlotTime(previousStart, previous.Step, i)
+					break
+				}
+			}
+		}
+	}
+
+	currentStart, _ := time.Parse(time.RFC3339, current.Start)
+
+	for _, stack := range current.Stacks {
+		for _, serie := range stack.Series {
+			index := 0
+
+			if lastTime, ok := lastTimes[[2]string{stack.Name, serie.Name}]; ok {
+				for index < len(serie.Plots) && !getPlotTime(currentStart, current.Step, index).After(lastTime) {
+					index++
+				}
+			}
+
+			if index == len(serie.Plots) || known[[2]string{stack.Name, serie.Name}] &&
+				!hasPlotValues(serie.Plots[index:]) {
+				continue
+			}
+
+			update.Series = append(update.Series, &StreamSerieResponse{
+				Stack: stack.Name,
+				Name:  serie.Name,
+				Start: getPlotTime(currentStart, current.Step, index).Format(time.RFC3339),
+				Plots: serie.Plots[index:],
+				Info:  serie.Info,
+			})
+		}
+	}
+
+	return update
+}
+
+func hasPlotValues(plots []types.PlotValue) bool {
+	for _, value := range plots {
+		if !math.IsNaN(float64(value)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getPlotTime(startTime time.Time, step float64, index int) time.Time {
+	return startTime.Add(time.Duration(float64(index) * step * float64(time.Second)))
+}
+
+func getStreamFrame(event string, data interface{}) []byte {
+	output, err := json.Marshal(data)
+	if err != nil {
+		log.Println("ERROR: " + err.Error())
+		output = []byte("null")
+	}
+
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, output))
+}
+
+func newPlotStream(plotReq *PlotRequest, refresh time.Duration) (*plotStream, error) {
+	now := time.Now()
+
+	startTime, err := utils.TimeApplyRange(now, plotReq.Range)
+	if err != nil {
+		return nil, err
+	} else if !startTime.Before(now) {
+		return nil, fmt.Errorf("stream range must be negative")
+	}
+
+	if plotReq.Sample == 0 {
+		plotReq.Sample = config.DefaultPlotSample
+	}
+
+	stream := &plotStream{
+		plotReq:     *plotReq,
+		duration:    now.Sub(startTime),
+		refresh:     refresh,
+		subscribers: make(map[chan []byte]bool),
+		stopChan:    make(chan bool),
+	}
+
+	// Use whole seconds steps as time boundaries are passed using RFC 3339 timestamps
+	stream.step = (stream.duration / time.Duration(plotReq.Sample)).Truncate(time.Second)
+	if stream.step < time.Second {
+		stream.step = time.Second
+	}
+
+	// Share streams among subscribers of identical requests
+	key, err := json.Marshal(plotReq)
+	if err != nil {
+		return nil, err
+	}
+
+	stream.key = string(key) + " " + refresh.String()
+
+	return stream, nil
+}
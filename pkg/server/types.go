@@ -1,8 +1,11 @@
 package server
 
 import (
+	"encoding/json"
 	"time"
 
+	"github.com/facette/facette/pkg/cache"
+	"github.com/facette/facette/pkg/library"
 	"github.com/facette/facette/pkg/types"
 )
 
@@ -23,17 +26,41 @@ func (e ExpandRequest) Swap(i, j int) {
 
 // PlotRequest represents a plot request structure in the server backend.
 type PlotRequest struct {
-	Time        string    `json:"time"`
-	Range       string    `json:"range"`
-	Sample      int       `json:"sample"`
-	Constants   []float64 `json:"constants"`
-	Percentiles []float64 `json:"percentiles"`
-	Graph       string    `json:"graph"`
-	Origin      string    `json:"origin"`
-	Source      string    `json:"source"`
-	Metric      string    `json:"metric"`
-	Template    string    `json:"template"`
-	Filter      string    `json:"filter"`
+	Time           string            `json:"time"`
+	Range          string            `json:"range"`
+	Start          string            `json:"start"`
+	End            string            `json:"end"`
+	Timezone       string            `json:"timezone"`
+	Sample         int               `json:"sample"`
+	Downsample     string            `json:"downsample"`
+	Constants      []float64         `json:"constants"`
+	Percentiles    []float64         `json:"percentiles"`
+	Graph          string            `json:"graph"`
+	Origin         string            `json:"origin"`
+	Source         string            `json:"source"`
+	Metric         string            `json:"metric"`
+	Template       string            `json:"template"`
+	Filter         string            `json:"filter"`
+	Attributes     map[string]string `json:"attributes"`
+	Shifts         []string          `json:"shifts"`
+	Annotations    bool              `json:"annotations"`
+	AnnotationTags []string          `json:"annotation_tags"`
+}
+
+// SnapshotRequest represents a snapshot creation request structure in the server backend.
+type SnapshotRequest struct {
+	PlotRequest
+	Name       string `json:"name"`
+	Collection string `json:"collection"`
+	Expire     string `json:"expire"`
+}
+
+// InstantiateRequest represents a graph template instantiation request structure in the server backend.
+type InstantiateRequest struct {
+	Template    string            `json:"template"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Attributes  map[string]string `json:"attributes"`
 }
 
 // OriginResponse represents an origin response structure in the server backend.
@@ -79,10 +106,11 @@ func (r StringListResponse) slice(i, j int) interface{} {
 
 // ItemResponse represents an item response structure in the server backend.
 type ItemResponse struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Modified    string `json:"modified"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Modified    string   `json:"modified"`
 }
 
 // ItemListResponse represents a list of items response structure in the server backend.
@@ -104,6 +132,31 @@ func (r ItemListResponse) slice(i, j int) interface{} {
 	return r[i:j]
 }
 
+// TagResponse represents a tag response structure in the server backend.
+type TagResponse struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// TagListResponse represents a list of tags response structure in the server backend.
+type TagListResponse []*TagResponse
+
+func (r TagListResponse) Len() int {
+	return len(r)
+}
+
+func (r TagListResponse) Less(i, j int) bool {
+	return r[i].Name < r[j].Name
+}
+
+func (r TagListResponse) Swap(i, j int) {
+	r[i], r[j] = r[j], r[i]
+}
+
+func (r TagListResponse) slice(i, j int) interface{} {
+	return r[i:j]
+}
+
 // CollectionResponse represents a collection response structure in the server backend.
 type CollectionResponse struct {
 	ItemResponse
@@ -132,16 +185,17 @@ func (r CollectionListResponse) slice(i, j int) interface{} {
 
 // PlotResponse represents a plot response structure in the server backend.
 type PlotResponse struct {
-	ID          string           `json:"id"`
-	Start       string           `json:"start"`
-	End         string           `json:"end"`
-	Step        float64          `json:"step"`
-	Name        string           `json:"name"`
-	Description string           `json:"description"`
-	Type        int              `json:"type"`
-	StackMode   int              `json:"stack_mode"`
-	Stacks      []*StackResponse `json:"stacks"`
-	Modified    time.Time        `json:"modified"`
+	ID          string                `json:"id"`
+	Start       string                `json:"start"`
+	End         string                `json:"end"`
+	Step        float64               `json:"step"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Type        int                   `json:"type"`
+	StackMode   int                   `json:"stack_mode"`
+	Stacks      []*StackResponse      `json:"stacks"`
+	Annotations []*AnnotationResponse `json:"annotations,omitempty"`
+	Modified    time.Time             `json:"modified"`
 }
 
 // StackResponse represents a stack response structure in the server backend.
@@ -152,10 +206,168 @@ type StackResponse struct {
 
 // SerieResponse represents a serie response structure in the server backend.
 type SerieResponse struct {
-	Name    string                     `json:"name"`
-	Plots   []types.PlotValue          `json:"plots"`
-	Info    map[string]types.PlotValue `json:"info"`
-	Options map[string]interface{}     `json:"options"`
+	Name       string                     `json:"name"`
+	Plots      []types.PlotValue          `json:"plots"`
+	Info       map[string]types.PlotValue `json:"info"`
+	Options    map[string]interface{}     `json:"options"`
+	Fill       string                     `json:"fill,omitempty"`
+	Comparison bool                       `json:"comparison,omitempty"`
+	Shift      string                     `json:"shift,omitempty"`
+	Forecast   bool                       `json:"forecast,omitempty"`
+	Anomaly    bool                       `json:"anomaly,omitempty"`
+	Band       string                     `json:"band,omitempty"`
+	Anomalies  []*AnomalyResponse         `json:"anomalies,omitempty"`
+}
+
+// StreamResponse represents a plots stream update response structure in the server backend.
+type StreamResponse struct {
+	Start  string                 `json:"start"`
+	End    string                 `json:"end"`
+	Step   float64                `json:"step"`
+	Series []*StreamSerieResponse `json:"series"`
+}
+
+// StreamSerieResponse represents a plots stream serie update response structure in the server backend.
+type StreamSerieResponse struct {
+	Stack string                     `json:"stack"`
+	Name  string                     `json:"name"`
+	Start string                     `json:"start"`
+	Plots []types.PlotValue          `json:"plots"`
+	Info  map[string]types.PlotValue `json:"info"`
+}
+
+// AnomalyResponse represents an anomalous interval response structure in the server backend.
+type AnomalyResponse struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// AlertResponse represents an alert rule state response structure in the server backend.
+type AlertResponse struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	State   string          `json:"state"`
+	Serie   string          `json:"serie,omitempty"`
+	Value   types.PlotValue `json:"value"`
+	Message string          `json:"message,omitempty"`
+	Since   string          `json:"since"`
+	Checked string          `json:"checked,omitempty"`
+}
+
+// AlertListResponse represents a list of alert rules states response structure in the server backend.
+type AlertListResponse []*AlertResponse
+
+func (r AlertListResponse) Len() int {
+	return len(r)
+}
+
+func (r AlertListResponse) Less(i, j int) bool {
+	return r[i].Name < r[j].Name
+}
+
+func (r AlertListResponse) Swap(i, j int) {
+	r[i], r[j] = r[j], r[i]
+}
+
+func (r AlertListResponse) slice(i, j int) interface{} {
+	return r[i:j]
+}
+
+// NotificationResponse represents an alert notification response structure in the server backend.
+type NotificationResponse struct {
+	Alert    string          `json:"alert"`
+	Name     string          `json:"name"`
+	Channel  string          `json:"channel"`
+	State    string          `json:"state"`
+	Previous string          `json:"previous"`
+	Serie    string          `json:"serie,omitempty"`
+	Value    types.PlotValue `json:"value"`
+	Message  string          `json:"message,omitempty"`
+	Repeat   bool            `json:"repeat,omitempty"`
+	Flapping bool            `json:"flapping,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Time     string          `json:"time"`
+}
+
+// NotificationListResponse represents a list of alert notifications response structure in the server backend.
+type NotificationListResponse []*NotificationResponse
+
+func (r NotificationListResponse) Len() int {
+	return len(r)
+}
+
+func (r NotificationListResponse) Less(i, j int) bool {
+	return r[i].Time > r[j].Time
+}
+
+func (r NotificationListResponse) Swap(i, j int) {
+	r[i], r[j] = r[j], r[i]
+}
+
+func (r NotificationListResponse) slice(i, j int) interface{} {
+	return r[i:j]
+}
+
+// AnnotationResponse represents an annotation response structure in the server backend.
+type AnnotationResponse struct {
+	ID       string   `json:"id"`
+	Start    string   `json:"start"`
+	End      string   `json:"end,omitempty"`
+	Title    string   `json:"title"`
+	Text     string   `json:"text"`
+	Tags     []string `json:"tags"`
+	Origin   string   `json:"origin"`
+	Source   string   `json:"source"`
+	Modified string   `json:"modified"`
+}
+
+// AnnotationListResponse represents a list of annotations response structure in the server backend.
+type AnnotationListResponse []*AnnotationResponse
+
+func (r AnnotationListResponse) Len() int {
+	return len(r)
+}
+
+func (r AnnotationListResponse) Less(i, j int) bool {
+	return r[i].Start < r[j].Start
+}
+
+func (r AnnotationListResponse) Swap(i, j int) {
+	r[i], r[j] = r[j], r[i]
+}
+
+func (r AnnotationListResponse) slice(i, j int) interface{} {
+	return r[i:j]
+}
+
+// SnapshotResponse represents a snapshot response structure in the server backend.
+type SnapshotResponse struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Graph      string            `json:"graph,omitempty"`
+	Collection string            `json:"collection,omitempty"`
+	Created    string            `json:"created"`
+	Expires    string            `json:"expires,omitempty"`
+	Plots      []json.RawMessage `json:"plots,omitempty"`
+}
+
+// SnapshotListResponse represents a list of snapshots response structure in the server backend.
+type SnapshotListResponse []*SnapshotResponse
+
+func (r SnapshotListResponse) Len() int {
+	return len(r)
+}
+
+func (r SnapshotListResponse) Less(i, j int) bool {
+	return r[i].Created < r[j].Created
+}
+
+func (r SnapshotListResponse) Swap(i, j int) {
+	r[i], r[j] = r[j], r[i]
+}
+
+func (r SnapshotListResponse) slice(i, j int) interface{} {
+	return r[i:j]
 }
 
 // Unexported types
@@ -176,6 +388,11 @@ type serverResponse struct {
 	Message string `json:"message"`
 }
 
+type importErrorResponse struct {
+	serverResponse
+	Report []*library.BundleReportEntry `json:"report"`
+}
+
 type statsResponse struct {
 	Origins        int    `json:"origins"`
 	Sources        int    `json:"sources"`
@@ -185,6 +402,10 @@ type statsResponse struct {
 	Graphs      int `json:"graphs"`
 	Collections int `json:"collections"`
 	Groups      int `json:"groups"`
+	Alerts      int `json:"alerts"`
+	Playlists   int `json:"playlists"`
+
+	Cache cache.Stats `json:"cache"`
 }
 
 type resourceResponse struct {
@@ -0,0 +1,222 @@
+// Package snapshot implements the storage of graphs plots frozen over a time range, shared using public links.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/facette/facette/pkg/utils"
+	"github.com/facette/facette/thirdparty/github.com/nu7hatch/gouuid"
+)
+
+// Snapshot represents a set of graphs plots frozen over a time range. Once stored, snapshots are immutable and are
+// only kept until their expiry time if set.
+type Snapshot struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Graph      string            `json:"graph,omitempty"`
+	Collection string            `json:"collection,omitempty"`
+	Created    time.Time         `json:"created"`
+	Expires    *time.Time        `json:"expires,omitempty"`
+	Plots      []json.RawMessage `json:"plots,omitempty"`
+}
+
+// Store represents the main structure of a snapshots store instance. Only snapshots metadata are kept in memory,
+// plots data being loaded from the filesystem on request.
+type Store struct {
+	Path       string
+	snapshots  map[string]*Snapshot
+	mutex      sync.RWMutex
+	debugLevel int
+}
+
+// Expired returns whether the snapshot has expired at a given time or not.
+func (snapshot *Snapshot) Expired(refTime time.Time) bool {
+	return snapshot.Expires != nil && !snapshot.Expires.After(refTime)
+}
+
+// Refresh updates the store by loading the snapshots stored on the filesystem, removing the expired ones.
+func (store *Store) Refresh() error {
+	snapshots := make(map[string]*Snapshot)
+	expired := make([]string, 0)
+
+	now := time.Now()
+
+	walkFunc := func(filePath string, fileInfo os.FileInfo, fileError error) error {
+		if fileInfo == nil || fileInfo.IsDir() || !strings.HasSuffix(filePath, ".json") {
+			return nil
+		}
+
+		snapshot := &Snapshot{}
+
+		if _, err := utils.JSONLoad(filePath, snapshot); err != nil {
+			return fmt.Errorf("in %s, %s", filePath, err.Error())
+		}
+
+		if snapshot.Expired(now) {
+			expired = append(expired, filePath)
+			return nil
+		}
+
+		snapshot.Plots = nil
+		snapshots[snapshot.ID] = snapshot
+
+		return nil
+	}
+
+	if _, err := os.Stat(store.Path); err == nil {
+		if err := utils.WalkDir(store.Path, walkFunc); err != nil {
+			log.Println("ERROR: " + err.Error())
+			return err
+		}
+	}
+
+	for _, filePath := range expired {
+		if err := syscall.Unlink(filePath); err != nil {
+			log.Printf("ERROR: unable to remove expired snapshot: %s", err)
+		}
+	}
+
+	if store.debugLevel > 0 {
+		log.Printf("DEBUG: loaded %d snapshot(s), removed %d expired one(s)", len(snapshots), len(expired))
+	}
+
+	store.mutex.Lock()
+	store.snapshots = snapshots
+	store.mutex.Unlock()
+
+	return nil
+}
+
+// Get returns an existing snapshot along with its plots data from the store. Expired snapshots are removed when
+// requested.
+func (store *Store) Get(id string) (*Snapshot, error) {
+	store.mutex.RLock()
+	item, ok := store.snapshots[id]
+	store.mutex.RUnlock()
+
+	if !ok {
+		return nil, os.ErrNotExist
+	} else if item.Expired(time.Now()) {
+		store.Delete(id)
+		return nil, os.ErrNotExist
+	}
+
+	snapshot := &Snapshot{}
+
+	if _, err := utils.JSONLoad(store.getFilePath(id), snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// List returns the metadata of the snapshots not having expired, sorted by creation time.
+func (store *Store) List() []*Snapshot {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	result := make(snapshotList, 0)
+
+	now := time.Now()
+
+	for _, snapshot := range store.snapshots {
+		if snapshot.Expired(now) {
+			continue
+		}
+
+		result = append(result, snapshot)
+	}
+
+	sort.Sort(result)
+
+	return result
+}
+
+// Store stores a new snapshot into the store, assigning it a new random identifier.
+func (store *Store) Store(snapshot *Snapshot) error {
+	if snapshot.ID != "" {
+		return os.ErrExist
+	} else if len(snapshot.Plots) == 0 || snapshot.Expired(snapshot.Created) {
+		log.Printf("ERROR: in `%s' snapshot, no plots or expiry before creation", snapshot.Name)
+		return os.ErrInvalid
+	}
+
+	uuidTemp, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	snapshot.ID = uuidTemp.String()
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err := utils.JSONDump(store.getFilePath(snapshot.ID), snapshot, snapshot.Created); err != nil {
+		return err
+	}
+
+	store.snapshots[snapshot.ID] = &Snapshot{
+		ID:         snapshot.ID,
+		Name:       snapshot.Name,
+		Graph:      snapshot.Graph,
+		Collection: snapshot.Collection,
+		Created:    snapshot.Created,
+		Expires:    snapshot.Expires,
+	}
+
+	return nil
+}
+
+// Delete removes an existing snapshot from the store.
+func (store *Store) Delete(id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, ok := store.snapshots[id]; !ok {
+		return os.ErrNotExist
+	}
+
+	if err := syscall.Unlink(store.getFilePath(id)); err != nil {
+		return err
+	}
+
+	delete(store.snapshots, id)
+
+	return nil
+}
+
+func (store *Store) getFilePath(id string) string {
+	return path.Join(store.Path, id+".json")
+}
+
+type snapshotList []*Snapshot
+
+func (list snapshotList) Len() int {
+	return len(list)
+}
+
+func (list snapshotList) Less(i, j int) bool {
+	return list[i].Created.Before(list[j].Created)
+}
+
+func (list snapshotList) Swap(i, j int) {
+	list[i], list[j] = list[j], list[i]
+}
+
+// NewStore creates a new instance of snapshots store.
+func NewStore(dirPath string, debugLevel int) *Store {
+	return &Store{
+		Path:       dirPath,
+		snapshots:  make(map[string]*Snapshot),
+		debugLevel: debugLevel,
+	}
+}
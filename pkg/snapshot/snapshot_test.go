@@ -0,0 +1,102 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/facette/facette/pkg/utils"
+)
+
+func Test_StoreSnapshots(test *testing.T) {
+	dirPath, err := ioutil.TempDir("", "snapshots")
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	defer os.RemoveAll(dirPath)
+
+	store := NewStore(dirPath, 0)
+
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	expires := now.Add(time.Hour)
+
+	plots := []json.RawMessage{json.RawMessage(`{"name":"graph1","plots":[1,2,3]}`)}
+
+	snapshot := &Snapshot{Name: "incident", Graph: "graph1", Created: now, Expires: &expires, Plots: plots}
+
+	if err = store.Store(snapshot); err != nil || snapshot.ID == "" {
+		test.Logf("\nExpected snapshot to be stored\nbut got  %v", err)
+		test.FailNow()
+	}
+
+	for _, entry := range []struct {
+		snapshot *Snapshot
+		expected error
+	}{
+		{snapshot, os.ErrExist},
+		{&Snapshot{Name: "empty", Created: now}, os.ErrInvalid},
+		{&Snapshot{Name: "expired", Created: now, Expires: &expired, Plots: plots}, os.ErrInvalid},
+	} {
+		if err = store.Store(entry.snapshot); err != entry.expected {
+			test.Logf("\nExpected %s\nbut got  %v", entry.expected, err)
+			test.Fail()
+		}
+	}
+
+	// Write an expired snapshot directly to check its removal when refreshing
+	expiredPath := path.Join(dirPath, "expired.json")
+
+	utils.JSONDump(expiredPath, &Snapshot{ID: "expired", Name: "expired", Created: expired, Expires: &expired,
+		Plots: plots}, expired)
+
+	store = NewStore(dirPath, 0)
+
+	if err = store.Refresh(); err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	if _, err = os.Stat(expiredPath); !os.IsNotExist(err) {
+		test.Logf("\nExpected expired snapshot to be removed\nbut got  %v", err)
+		test.Fail()
+	}
+
+	if result := store.List(); len(result) != 1 || result[0].ID != snapshot.ID || result[0].Plots != nil {
+		test.Logf("\nExpected snapshot metadata\nbut got  %+v", result)
+		test.Fail()
+	}
+
+	// Check snapshot is loaded back along with its plots data
+	result, err := store.Get(snapshot.ID)
+	if err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.FailNow()
+	}
+
+	var plot struct {
+		Name  string    `json:"name"`
+		Plots []float64 `json:"plots"`
+	}
+
+	if result.Name != "incident" || len(result.Plots) != 1 || json.Unmarshal(result.Plots[0], &plot) != nil ||
+		plot.Name != "graph1" || len(plot.Plots) != 3 {
+		test.Logf("\nExpected snapshot plots data\nbut got  %+v", result)
+		test.Fail()
+	}
+
+	if err = store.Delete(snapshot.ID); err != nil {
+		test.Logf("\nExpected no error\nbut got  %s", err)
+		test.Fail()
+	}
+
+	if _, err = store.Get(snapshot.ID); err != os.ErrNotExist {
+		test.Logf("\nExpected %s\nbut got  %v", os.ErrNotExist, err)
+		test.Fail()
+	}
+}
@@ -16,3 +16,20 @@ func RequestGetContentType(request *http.Request) string {
 
 	return contentType
 }
+
+// RequestGetAccept returns the list of media types found in the HTTP request `Accept' header value.
+func RequestGetAccept(request *http.Request) []string {
+	result := make([]string, 0)
+
+	for _, chunk := range strings.Split(request.Header.Get("Accept"), ",") {
+		if index := strings.Index(chunk, ";"); index != -1 {
+			chunk = chunk[:index]
+		}
+
+		if chunk = strings.TrimSpace(chunk); chunk != "" {
+			result = append(result, chunk)
+		}
+	}
+
+	return result
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"net/http"
+	"reflect"
 	"testing"
 )
 
@@ -30,3 +31,24 @@ func Test_RequestGetContentType(test *testing.T) {
 		test.Fail()
 	}
 }
+
+func Test_RequestGetAccept(test *testing.T) {
+	request, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		test.Fatal(err.Error())
+	}
+
+	if result := RequestGetAccept(request); len(result) != 0 {
+		test.Logf("\nExpected %#v\nbut got  %#v", []string{}, result)
+		test.Fail()
+	}
+
+	request.Header.Add("Accept", "text/csv;q=0.9, application/json")
+
+	expected := []string{"text/csv", "application/json"}
+
+	if result := RequestGetAccept(request); !reflect.DeepEqual(result, expected) {
+		test.Logf("\nExpected %#v\nbut got  %#v", expected, result)
+		test.Fail()
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,11 +13,16 @@ const (
 	durationRegexp = "^([-+])?\\s*" +
 		"(?:(\\d+)\\s*y(?:ears?)?)?\\s*" +
 		"(?:(\\d+)\\s*mo(?:nths?)?)?\\s*" +
+		"(?:(\\d+)\\s*w(?:eeks?)?)?\\s*" +
 		"(?:(\\d+)\\s*d(?:ays?)?)?\\s*" +
 		"(?:(\\d+)\\s*h(?:ours?)?)?\\s*" +
 		"(?:(\\d+)\\s*m(?:inutes?)?)?\\s*" +
 		"(?:(\\d+)\\s*s(?:econds?)?)?" +
 		"$"
+
+	timeExprRegexp = "^(now|today|yesterday|tomorrow)\\s*" +
+		"(\\d{1,2}:\\d{2}(?::\\d{2})?)?\\s*" +
+		"([-+].*)?$"
 )
 
 // TimeApplyRange applies a string-defined time range to a specific date.
@@ -58,10 +64,81 @@ func TimeApplyRange(refTime time.Time, input string) (time.Time, error) {
 	}
 
 	newTime := refTime.
-		AddDate(chunks[0], chunks[1], chunks[2]).
-		Add(time.Duration(chunks[3]) * time.Hour).
-		Add(time.Duration(chunks[4]) * time.Minute).
-		Add(time.Duration(chunks[5]) * time.Second)
+		AddDate(chunks[0], chunks[1], chunks[2]*7+chunks[3]).
+		Add(time.Duration(chunks[4]) * time.Hour).
+		Add(time.Duration(chunks[5]) * time.Minute).
+		Add(time.Duration(chunks[6]) * time.Second)
 
 	return newTime, nil
 }
+
+// TimeParse parses a string-defined time relative to a reference time. Inputs can be RFC 3339 dates, Unix timestamps,
+// dates with optional time of day (e.g. `2014-01-02 15:04'), or expressions based on `now', `today', `yesterday' or
+// `tomorrow' keywords with an optional time of day and range (e.g. `now-6h', `yesterday 09:00'). Times not carrying
+// an explicit offset are interpreted in the reference time location.
+func TimeParse(refTime time.Time, input string) (time.Time, error) {
+	input = strings.TrimSpace(input)
+
+	if input == "" {
+		return time.Time{}, fmt.Errorf("empty time")
+	}
+
+	if result, err := time.Parse(time.RFC3339, input); err == nil {
+		return result, nil
+	}
+
+	// Handle Unix timestamps
+	if timestamp, err := strconv.ParseFloat(input, 64); err == nil {
+		seconds := math.Floor(timestamp)
+		return time.Unix(int64(seconds), int64((timestamp-seconds)*1e9)).In(refTime.Location()), nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if result, err := time.ParseInLocation(layout, input, refTime.Location()); err == nil {
+			return result, nil
+		}
+	}
+
+	// Handle keywords expressions
+	match := regexp.MustCompile(timeExprRegexp).FindStringSubmatch(strings.ToLower(input))
+	if match == nil {
+		return time.Time{}, fmt.Errorf("unable to parse `%s' time", input)
+	}
+
+	year, month, day := refTime.Date()
+	result := time.Date(year, month, day, 0, 0, 0, 0, refTime.Location())
+
+	switch match[1] {
+	case "now":
+		if match[2] != "" {
+			return time.Time{}, fmt.Errorf("unexpected time of day after `now' in `%s'", input)
+		}
+
+		result = refTime
+	case "yesterday":
+		result = result.AddDate(0, 0, -1)
+	case "tomorrow":
+		result = result.AddDate(0, 0, 1)
+	}
+
+	if match[2] != "" {
+		chunks := make([]int, 3)
+
+		for index, value := range strings.Split(match[2], ":") {
+			chunks[index], _ = strconv.Atoi(value)
+		}
+
+		if chunks[0] > 23 || chunks[1] > 59 || chunks[2] > 59 {
+			return time.Time{}, fmt.Errorf("invalid `%s' time of day", match[2])
+		}
+
+		result = time.Date(result.Year(), result.Month(), result.Day(), chunks[0], chunks[1], chunks[2], 0,
+			result.Location())
+	}
+
+	if match[3] != "" {
+		return TimeApplyRange(result, match[3])
+	}
+
+	return result, nil
+}
@@ -8,25 +8,65 @@ import (
 func Test_TimeApplyRange(test *testing.T) {
 	refTime := time.Now()
 
-	if result, _ := TimeApplyRange(refTime, "-1h"); result != refTime.Add(-1*time.Hour) {
+	if result, _ := TimeApplyRange(refTime, "-1h"); !result.Equal(refTime.Add(-1 * time.Hour)) {
 		test.Logf("\nExpected %#v\nbut got  %#v", refTime.Add(-1*time.Hour), result)
 		test.Fail()
 	}
 
-	if result, _ := TimeApplyRange(refTime, "2mo"); result != refTime.AddDate(0, 2, 0) {
+	if result, _ := TimeApplyRange(refTime, "2mo"); !result.Equal(refTime.AddDate(0, 2, 0)) {
 		test.Logf("\nExpected %#v\nbut got  %#v", refTime.AddDate(0, 2, 0), result)
 		test.Fail()
 	}
 
-	if result, _ := TimeApplyRange(refTime, "-1y 3h 126s"); result != refTime.AddDate(-1, 0, 0).
-		Add(-3*time.Hour-126*time.Second) {
+	if result, _ := TimeApplyRange(refTime, "-1y 3h 126s"); !result.Equal(refTime.AddDate(-1, 0, 0).
+		Add(-3*time.Hour - 126*time.Second)) {
 		test.Logf("\nExpected %#v\nbut got  %#v", refTime.AddDate(-1, 0, 0).Add(-3*time.Hour+126*time.Second), result)
 		test.Fail()
 	}
 
-	if result, _ := TimeApplyRange(refTime, "3d 1h 6m"); result != refTime.AddDate(0, 0, 3).
-		Add(time.Hour+6*time.Minute) {
+	if result, _ := TimeApplyRange(refTime, "3d 1h 6m"); !result.Equal(refTime.AddDate(0, 0, 3).
+		Add(time.Hour + 6*time.Minute)) {
 		test.Logf("\nExpected %#v\nbut got  %#v", refTime.AddDate(0, 0, 3).Add(time.Hour+6*time.Minute), result)
 		test.Fail()
 	}
+
+	if result, _ := TimeApplyRange(refTime, "-1w 2d"); !result.Equal(refTime.AddDate(0, 0, -9)) {
+		test.Logf("\nExpected %#v\nbut got  %#v", refTime.AddDate(0, 0, -9), result)
+		test.Fail()
+	}
+}
+
+func Test_TimeParse(test *testing.T) {
+	location := time.FixedZone("UTC+2", 2*3600)
+	refTime := time.Date(2014, 3, 12, 15, 30, 0, 0, location)
+
+	for input, expected := range map[string]time.Time{
+		"2014-01-02T03:04:05Z":  time.Date(2014, 1, 2, 3, 4, 5, 0, time.UTC),
+		"1389582245":            time.Unix(1389582245, 0),
+		"1389582245.5":          time.Unix(1389582245, 5e8),
+		"2014-01-02":            time.Date(2014, 1, 2, 0, 0, 0, 0, location),
+		"2014-01-02 14:03":      time.Date(2014, 1, 2, 14, 3, 0, 0, location),
+		"now":                   refTime,
+		"now-6h":                refTime.Add(-6 * time.Hour),
+		"now + 1d":              refTime.AddDate(0, 0, 1),
+		"today":                 time.Date(2014, 3, 12, 0, 0, 0, 0, location),
+		"yesterday 09:00":       time.Date(2014, 3, 11, 9, 0, 0, 0, location),
+		"Tomorrow 9:15:30 -30m": time.Date(2014, 3, 13, 8, 45, 30, 0, location),
+	} {
+		result, err := TimeParse(refTime, input)
+		if err != nil {
+			test.Logf("\nExpected %s\nbut got  error: %s (input: %s)", expected, err, input)
+			test.Fail()
+		} else if !result.Equal(expected) {
+			test.Logf("\nExpected %s\nbut got  %s (input: %s)", expected, result, input)
+			test.Fail()
+		}
+	}
+
+	for _, input := range []string{"", "later", "now 09:00", "today 25:00", "today -1x", "2014-13-01"} {
+		if _, err := TimeParse(refTime, input); err == nil {
+			test.Logf("\nExpected error\nbut got  none (input: %s)", input)
+			test.Fail()
+		}
+	}
 }